@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hayeah/fork2/internal/selection"
+	"github.com/hayeah/fork2/render"
+)
+
+// CheckCmd defines the command-line arguments for the check subcommand
+type CheckCmd struct {
+	Pattern string `arg:"positional" help:"Glob selecting the templates to check (default **/*.md)"`
+	Mode    string `arg:"--mode,-m" help:"Template specialization mode"`
+	JSON    bool   `arg:"--json" help:"Print problems as JSON, grouped by file"`
+}
+
+// defaultCheckPattern selects every template when no pattern is given
+const defaultCheckPattern = "**/*.md"
+
+// checkFileResult is the JSON form of the problems found in one template
+type checkFileResult struct {
+	File   string             `json:"file"`
+	Errors []render.LintError `json:"errors"`
+}
+
+// CheckRunner lints every template visible to the resolver
+type CheckRunner struct {
+	Args     CheckCmd
+	RootPath string
+	Output   io.Writer
+}
+
+// NewCheckRunner creates and initializes a new CheckRunner
+func NewCheckRunner(cmd CheckCmd, root string) (*CheckRunner, error) {
+	return &CheckRunner{
+		Args:     cmd,
+		RootPath: root,
+		Output:   os.Stdout,
+	}, nil
+}
+
+// Run executes the check subcommand
+func (r *CheckRunner) Run() error {
+	resolver, err := templateResolver(r.RootPath)
+	if err != nil {
+		return err
+	}
+
+	paths, err := resolver.ListTemplates("")
+	if err != nil {
+		return err
+	}
+
+	pattern := r.Args.Pattern
+	if pattern == "" {
+		pattern = defaultCheckPattern
+	}
+	matcher, err := selection.NewGlobMatcher(pattern)
+	if err != nil {
+		return err
+	}
+	paths, err = matcher.Match(paths)
+	if err != nil {
+		return err
+	}
+
+	resolver.Mode = r.Args.Mode
+	renderer := render.NewRenderer(resolver, nil)
+
+	var problems int
+	results := []checkFileResult{}
+	for _, p := range paths {
+		errs := renderer.Lint(p)
+		if len(errs) == 0 {
+			continue
+		}
+		problems += len(errs)
+		results = append(results, checkFileResult{File: p, Errors: errs})
+	}
+
+	if r.Args.JSON {
+		enc := json.NewEncoder(r.Output)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, res := range results {
+			for _, lerr := range res.Errors {
+				// vet-style tools expect file:line:col, so default to column 1
+				fmt.Fprintf(r.Output, "%s:%d:%d: %s\n", lerr.File, lerr.Line, max(lerr.Col, 1), lerr.Message)
+			}
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("check found %d problem(s) in %d template(s)", problems, len(paths))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+// createCheckProject writes a template tree with one bad template at the
+// root and another under parts/.
+func createCheckProject(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VIBE_PROMPTS", "")
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"good.md":         "---toml\nlayout = \"layout.md\"\n---\n{{ partial \"parts/footer\" }}",
+		"layout.md":       "{{ .Content }}",
+		"parts/footer.md": "footer",
+		"parts/broken.md": "{{ if }}",
+		"bad.md":          "---toml\nselct = \".go\"\n---\n{{ partial \"missing.md\" }}",
+		".hidden/skip.md": "{{ if }}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tempDir
+}
+
+func TestCheckRunner(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir := createCheckProject(t)
+
+	runner, err := NewCheckRunner(CheckCmd{}, tempDir)
+	assert.NoError(err)
+	var out bytes.Buffer
+	runner.Output = &out
+
+	err = runner.Run()
+	assert.Error(err)
+	assert.Equal(
+		"bad.md:2:1: unknown front matter field \"selct\" (did you mean \"select\"?)\n"+
+			"bad.md:4:4: partial \"missing.md\" not found\n"+
+			"parts/broken.md:1:1: missing value for if\n",
+		out.String())
+}
+
+func TestCheckRunner_Pattern(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir := createCheckProject(t)
+
+	runner, err := NewCheckRunner(CheckCmd{Pattern: "parts/**"}, tempDir)
+	assert.NoError(err)
+	var out bytes.Buffer
+	runner.Output = &out
+
+	assert.Error(runner.Run())
+	assert.Equal("parts/broken.md:1:1: missing value for if\n", out.String())
+
+	runner, err = NewCheckRunner(CheckCmd{Pattern: "good.md"}, tempDir)
+	assert.NoError(err)
+	out.Reset()
+	runner.Output = &out
+
+	assert.NoError(runner.Run())
+	assert.Equal("", out.String())
+}
+
+func TestCheckRunner_JSON(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir := createCheckProject(t)
+
+	runner, err := NewCheckRunner(CheckCmd{Pattern: "bad.md", JSON: true}, tempDir)
+	assert.NoError(err)
+	var out bytes.Buffer
+	runner.Output = &out
+
+	assert.Error(runner.Run())
+
+	var results []checkFileResult
+	assert.NoError(json.Unmarshal(out.Bytes(), &results))
+	assert.Equal(1, len(results))
+	assert.Equal("bad.md", results[0].File)
+	assert.Equal(2, len(results[0].Errors))
+	assert.Equal(4, results[0].Errors[1].Line)
+	assert.Equal(4, results[0].Errors[1].Col)
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// CompletionCmd defines the command-line arguments for the completion subcommand
+type CompletionCmd struct {
+	Shell string `arg:"positional" help:"Shell to generate a completion script for: bash, zsh, fish or powershell"`
+	List  string `arg:"--list" help:"Print completion candidates instead of a script: layouts or select-history"`
+}
+
+// CompletionRunner prints shell completion scripts, and the dynamic
+// candidates those scripts ask for at completion time
+type CompletionRunner struct {
+	Args     CompletionCmd
+	RootPath string
+	Output   io.Writer
+}
+
+// selectHistoryLimit is the number of --select patterns kept in the history file
+const selectHistoryLimit = 50
+
+// NewCompletionRunner creates and initializes a new CompletionRunner
+func NewCompletionRunner(cmd CompletionCmd, root string) (*CompletionRunner, error) {
+	switch {
+	case cmd.List != "":
+		if cmd.List != "layouts" && cmd.List != "select-history" {
+			return nil, fmt.Errorf("unknown completion list %q, use 'layouts' or 'select-history'", cmd.List)
+		}
+	case cmd.Shell == "":
+		return nil, fmt.Errorf("a shell must be provided: bash, zsh, fish or powershell")
+	}
+
+	return &CompletionRunner{
+		Args:     cmd,
+		RootPath: root,
+		Output:   os.Stdout,
+	}, nil
+}
+
+// Run executes the completion subcommand
+func (r *CompletionRunner) Run() error {
+	if r.Args.List != "" {
+		return r.runList()
+	}
+
+	cmds := subcommandSpecs()
+	var script string
+	switch r.Args.Shell {
+	case "bash":
+		script = bashCompletion(cmds)
+	case "zsh":
+		script = "autoload -U +X bashcompinit && bashcompinit\n" + bashCompletion(cmds)
+	case "fish":
+		script = fishCompletion(cmds)
+	case "powershell":
+		script = powershellCompletion(cmds)
+	default:
+		return fmt.Errorf("unsupported shell %q, use bash, zsh, fish or powershell", r.Args.Shell)
+	}
+
+	_, err := io.WriteString(r.Output, script)
+	return err
+}
+
+// runList prints one completion candidate per line
+func (r *CompletionRunner) runList() error {
+	var values []string
+	switch r.Args.List {
+	case "layouts":
+		resolver, err := templateResolver(r.RootPath)
+		if err != nil {
+			return err
+		}
+		paths, err := resolver.ListTemplates("")
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			values = append(values, strings.TrimSuffix(p, ".md"))
+		}
+	case "select-history":
+		values = readSelectHistory()
+	}
+
+	for _, v := range values {
+		if _, err := fmt.Fprintln(r.Output, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectHistoryPath returns the file recording recent --select patterns
+func selectHistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".vibe", "select_history"), nil
+}
+
+// readSelectHistory returns recorded --select patterns, most recent first.
+// A missing or unreadable history file yields no patterns.
+func readSelectHistory() []string {
+	path, err := selectHistoryPath()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	slices.Reverse(patterns)
+	return patterns
+}
+
+// recordSelectHistory moves pattern to the end of the history file, keeping
+// at most selectHistoryLimit entries.
+func recordSelectHistory(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.Contains(pattern, "\n") {
+		return nil
+	}
+	path, err := selectHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	// readSelectHistory is newest first; the file is oldest first
+	history := readSelectHistory()
+	slices.Reverse(history)
+	history = slices.DeleteFunc(history, func(p string) bool { return p == pattern })
+	history = append(history, pattern)
+	if len(history) > selectHistoryLimit {
+		history = history[len(history)-selectHistoryLimit:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0644)
+}
+
+// subcommandSpec describes a subcommand and its flags for completion scripts
+type subcommandSpec struct {
+	Name  string
+	Flags []string // long and short forms, e.g. "--select", "-s"
+}
+
+// subcommandSpecs derives the subcommands and their flags from the go-arg
+// tags on Args, so completion stays in sync with the CLI definition.
+func subcommandSpecs() []subcommandSpec {
+	var specs []subcommandSpec
+	argsType := reflect.TypeOf(Args{})
+	for i := 0; i < argsType.NumField(); i++ {
+		field := argsType.Field(i)
+		name, ok := strings.CutPrefix(field.Tag.Get("arg"), "subcommand:")
+		if !ok {
+			continue
+		}
+
+		spec := subcommandSpec{Name: name}
+		cmdType := field.Type.Elem()
+		for j := 0; j < cmdType.NumField(); j++ {
+			for _, part := range strings.Split(cmdType.Field(j).Tag.Get("arg"), ",") {
+				if strings.HasPrefix(part, "-") && part != "-" && !slices.Contains(spec.Flags, part) {
+					spec.Flags = append(spec.Flags, part)
+				}
+			}
+		}
+		spec.Flags = append(spec.Flags, "--help")
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func subcommandNames(cmds []subcommandSpec) []string {
+	names := make([]string, len(cmds))
+	for i, c := range cmds {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func bashCompletion(cmds []subcommandSpec) string {
+	var b strings.Builder
+	b.WriteString(`# bash completion for vibe
+_vibe() {
+    local cur prev sub
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    sub="${COMP_WORDS[1]}"
+
+    if [[ ${COMP_CWORD} -eq 1 ]]; then
+        COMPREPLY=( $(compgen -W "` + strings.Join(subcommandNames(cmds), " ") + `" -- "${cur}") )
+        return
+    fi
+
+    case "${prev}" in
+        --layout)
+            COMPREPLY=( $(compgen -W "$(vibe completion --list layouts 2>/dev/null)" -- "${cur}") )
+            return
+            ;;
+        -s|--select)
+            local IFS=$'\n'
+            COMPREPLY=( $(compgen -W "$(vibe completion --list select-history 2>/dev/null)" -- "${cur}") )
+            return
+            ;;
+    esac
+
+    if [[ "${cur}" == -* ]]; then
+        local flags=""
+        case "${sub}" in
+`)
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "            %s) flags=%q ;;\n", c.Name, strings.Join(c.Flags, " "))
+	}
+	b.WriteString(`        esac
+        COMPREPLY=( $(compgen -W "${flags}" -- "${cur}") )
+        return
+    fi
+
+    COMPREPLY=( $(compgen -f -- "${cur}") )
+}
+complete -o filenames -o bashdefault -F _vibe vibe
+`)
+	return b.String()
+}
+
+func fishCompletion(cmds []subcommandSpec) string {
+	var b strings.Builder
+	b.WriteString("# fish completion for vibe\n")
+	fmt.Fprintf(&b, "complete -c vibe -f -n __fish_use_subcommand -a %q\n", strings.Join(subcommandNames(cmds), " "))
+	for _, c := range cmds {
+		cond := fmt.Sprintf("__fish_seen_subcommand_from %s", c.Name)
+		for _, flag := range c.Flags {
+			opt := "-s " + strings.TrimPrefix(flag, "-")
+			if strings.HasPrefix(flag, "--") {
+				opt = "-l " + strings.TrimPrefix(flag, "--")
+			}
+			extra := ""
+			switch flag {
+			case "--layout":
+				extra = ` -x -a "(vibe completion --list layouts 2>/dev/null)"`
+			case "--select", "-s":
+				extra = ` -x -a "(vibe completion --list select-history 2>/dev/null)"`
+			}
+			fmt.Fprintf(&b, "complete -c vibe -n %q %s%s\n", cond, opt, extra)
+		}
+	}
+	return b.String()
+}
+
+func powershellCompletion(cmds []subcommandSpec) string {
+	var b strings.Builder
+	b.WriteString(`# powershell completion for vibe
+Register-ArgumentCompleter -Native -CommandName vibe -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = $commandAst.CommandElements | ForEach-Object { $_.ToString() }
+    $flags = @{
+`)
+	for _, c := range cmds {
+		quoted := make([]string, len(c.Flags))
+		for i, f := range c.Flags {
+			quoted[i] = "'" + f + "'"
+		}
+		fmt.Fprintf(&b, "        '%s' = @(%s)\n", c.Name, strings.Join(quoted, ", "))
+	}
+	b.WriteString(`    }
+    $candidates = @()
+    if ($words.Count -le 1 -or ($words.Count -eq 2 -and $wordToComplete -ne '')) {
+        $candidates = $flags.Keys
+    } elseif ($words[-1] -eq '--layout' -or ($words[-2] -eq '--layout' -and $wordToComplete -ne '')) {
+        $candidates = vibe completion --list layouts 2>$null
+    } elseif ($words[-1] -in '-s', '--select' -or ($words[-2] -in '-s', '--select' -and $wordToComplete -ne '')) {
+        $candidates = vibe completion --list select-history 2>$null
+    } elseif ($wordToComplete -like '-*') {
+        $candidates = $flags[$words[1]]
+    } else {
+        return
+    }
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`)
+	return b.String()
+}
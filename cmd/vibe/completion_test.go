@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestCompletionRunner(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VIBE_PROMPTS", "")
+
+	run := func(t *testing.T, cmd CompletionCmd, root string) string {
+		runner, err := NewCompletionRunner(cmd, root)
+		assert.New(t).NoError(err)
+		var out bytes.Buffer
+		runner.Output = &out
+		assert.New(t).NoError(runner.Run())
+		return out.String()
+	}
+
+	t.Run("bash script", func(t *testing.T) {
+		assert := assert.New(t)
+		script := run(t, CompletionCmd{Shell: "bash"}, ".")
+		assert.Contains(script, `compgen -W "out diff ls new init check list-templates completion profile install:vscode:tasks"`)
+		assert.Contains(script, "vibe completion --list layouts")
+		assert.Contains(script, "vibe completion --list select-history")
+		assert.Contains(script, "complete -o filenames -o bashdefault -F _vibe vibe")
+		// flags are derived from the subcommand structs, without duplicates
+		assert.Contains(script, `ls) flags="-s --select --mode -m --tokens --sort-by --token-estimator --help"`)
+	})
+
+	t.Run("other shells", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.True(strings.HasPrefix(run(t, CompletionCmd{Shell: "zsh"}, "."), "autoload -U +X bashcompinit"))
+		assert.Contains(run(t, CompletionCmd{Shell: "fish"}, "."), `complete -c vibe -n "__fish_seen_subcommand_from out" -l layout -x`)
+		assert.Contains(run(t, CompletionCmd{Shell: "powershell"}, "."), "Register-ArgumentCompleter -Native -CommandName vibe")
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := NewCompletionRunner(CompletionCmd{}, ".")
+		assert.Error(err)
+		_, err = NewCompletionRunner(CompletionCmd{List: "bogus"}, ".")
+		assert.Error(err)
+
+		runner, err := NewCompletionRunner(CompletionCmd{Shell: "tcsh"}, ".")
+		assert.NoError(err)
+		assert.Error(runner.Run())
+	})
+
+	t.Run("layouts", func(t *testing.T) {
+		assert := assert.New(t)
+		root := t.TempDir()
+		for _, name := range []string{"files.md", "layouts/review.md", ".hidden/skip.md"} {
+			path := filepath.Join(root, name)
+			assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+			assert.NoError(os.WriteFile(path, []byte("x"), 0644))
+		}
+
+		out := run(t, CompletionCmd{List: "layouts"}, root)
+		assert.Contains(out, "files\n")
+		assert.Contains(out, "layouts/review\n")
+		assert.NotContains(out, "skip")
+	})
+
+	t.Run("select history", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal("", run(t, CompletionCmd{List: "select-history"}, "."))
+
+		for _, p := range []string{".go$", "*.md", ".go$", "  ", "git:modified"} {
+			assert.NoError(recordSelectHistory(p))
+		}
+		assert.Equal("git:modified\n.go$\n*.md\n", run(t, CompletionCmd{List: "select-history"}, "."))
+
+		for i := 0; i < selectHistoryLimit+5; i++ {
+			assert.NoError(recordSelectHistory(strings.Repeat("x", i+1)))
+		}
+		assert.Len(readSelectHistory(), selectHistoryLimit)
+	})
+}
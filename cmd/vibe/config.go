@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hayeah/fork2/internal/selection"
+)
+
+// Config holds defaults for the out command, read from vibe.toml.
+// Field names mirror the OutCmd flags.
+type Config struct {
+	TokenEstimator string   `toml:"token_estimator"`
+	Output         string   `toml:"output"`
+	Layout         string   `toml:"layout"`
+	Select         string   `toml:"select"`
+	Exclude        string   `toml:"exclude"`
+	SelectDirTree  string   `toml:"dirtree"`
+	Data           []string `toml:"data"`
+	DataFile       string   `toml:"data_file"`
+	Metrics        string   `toml:"metrics"`
+	MetricsFormat  string   `toml:"metrics_format"`
+	Content        []string `toml:"content"`
+	Mode           string   `toml:"mode"`
+	MaxDepth       int      `toml:"max_depth"`
+	NewerThan      string   `toml:"newer_than"` // a duration such as "48h"
+	MaxTokens      int      `toml:"max_tokens"`
+
+	// Profiles are named presets selected with --profile, e.g. [profiles.go-review]
+	Profiles map[string]Config `toml:"profiles"`
+}
+
+// TemplateDefaults are the front matter keys the config can set. Unlike the
+// flags they are only used where the template's front matter leaves a field
+// empty.
+type TemplateDefaults struct {
+	Layout  string
+	Select  string
+	Exclude string
+	Dirtree string
+}
+
+// configFileName is the per-project config file looked up in the root path
+const configFileName = "vibe.toml"
+
+// LoadConfig reads vibe.toml in rootPath, falling back to
+// ~/.config/vibe/config.toml. Having neither file is not an error.
+// Unknown keys are reported as warnings.
+func LoadConfig(rootPath string) (Config, error) {
+	candidates := []string{filepath.Join(rootPath, configFileName)}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".config", "vibe", "config.toml"))
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		var cfg Config
+		md, err := toml.DecodeFile(path, &cfg)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+		for _, key := range md.Undecoded() {
+			log.Printf("warning: %s: unknown config key %q", path, key.String())
+		}
+		if err := cfg.validate(); err != nil {
+			return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+		return cfg, nil
+	}
+
+	return Config{}, nil
+}
+
+// validate checks the values that are parsed later, in Apply.
+func (c Config) validate() error {
+	if c.NewerThan != "" {
+		if _, err := time.ParseDuration(c.NewerThan); err != nil {
+			return fmt.Errorf("newer_than: %w", err)
+		}
+	}
+	for name, p := range c.Profiles {
+		if len(p.Profiles) > 0 {
+			return fmt.Errorf("profile %s: profiles cannot be nested", name)
+		}
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyProfile is like Apply, but first fills cmd from the profile named by
+// cmd.Profile. Profiles in vibe.toml are looked up first, then the select
+// patterns saved with "vibe profile save". Flags on the command line win over
+// the profile, and the profile wins over the top-level config. Like the
+// config, a profile's layout, select, exclude and dirtree only fill in for
+// empty front matter, but a profile select without a template renders the
+// files template, just as --select does.
+func (c Config) ApplyProfile(cmd OutCmd) (OutCmd, error) {
+	if cmd.Profile != "" {
+		p, ok := c.Profiles[cmd.Profile]
+		if !ok {
+			store, err := selection.DefaultProfileStore()
+			if err != nil {
+				return OutCmd{}, err
+			}
+			saved, found, err := store.Get(cmd.Profile)
+			if err != nil {
+				return OutCmd{}, err
+			}
+			if !found {
+				return OutCmd{}, fmt.Errorf("unknown profile %q", cmd.Profile)
+			}
+			p = Config{Select: saved.Select}
+		}
+		cmd = p.Apply(cmd)
+		if cmd.Template == "" && !cmd.Stdin && cmd.Select == "" && cmd.Defaults.Select != "" {
+			cmd.Template = "files"
+		}
+	}
+	return c.Apply(cmd), nil
+}
+
+// Apply returns cmd with every flag left at its zero value filled in from
+// the config. Flags given on the command line always win. The layout,
+// select, exclude and dirtree keys go to cmd.Defaults instead, since a
+// template's front matter wins over them.
+func (c Config) Apply(cmd OutCmd) OutCmd {
+	setString := func(dst *string, v string) {
+		if *dst == "" {
+			*dst = v
+		}
+	}
+	setString(&cmd.TokenEstimator, c.TokenEstimator)
+	setString(&cmd.Output, c.Output)
+	setString(&cmd.Defaults.Layout, c.Layout)
+	setString(&cmd.Defaults.Select, c.Select)
+	setString(&cmd.Defaults.Exclude, c.Exclude)
+	setString(&cmd.Defaults.Dirtree, c.SelectDirTree)
+	setString(&cmd.DataFile, c.DataFile)
+	setString(&cmd.Metrics, c.Metrics)
+	setString(&cmd.MetricsFormat, c.MetricsFormat)
+	setString(&cmd.Mode, c.Mode)
+
+	if len(cmd.Data) == 0 {
+		cmd.Data = c.Data
+	}
+	if len(cmd.Content) == 0 {
+		cmd.Content = c.Content
+	}
+	if cmd.MaxDepth == 0 {
+		cmd.MaxDepth = c.MaxDepth
+	}
+	if cmd.MaxTokens == 0 {
+		cmd.MaxTokens = c.MaxTokens
+	}
+	if cmd.NewerThan == nil && c.NewerThan != "" {
+		// validated by LoadConfig
+		if d, err := time.ParseDuration(c.NewerThan); err == nil {
+			cmd.NewerThan = &d
+		}
+	}
+	return cmd
+}
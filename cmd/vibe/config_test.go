@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	writeFile := func(t *testing.T, path, content string) {
+		assert.New(t).NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.New(t).NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	t.Run("no config files", func(t *testing.T) {
+		assert := assert.New(t)
+		cfg, err := LoadConfig(t.TempDir())
+		assert.NoError(err)
+		assert.Equal(Config{}, cfg)
+	})
+
+	t.Run("project config", func(t *testing.T) {
+		assert := assert.New(t)
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, "vibe.toml"), `
+token_estimator = "tiktoken"
+layout = "files"
+metrics = "metrics.csv"
+data = ["model=gpt4"]
+max_depth = 3
+newer_than = "48h"
+colour = "blue"
+`)
+
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		cfg, err := LoadConfig(root)
+		assert.NoError(err)
+		assert.Equal(Config{
+			TokenEstimator: "tiktoken",
+			Layout:         "files",
+			Metrics:        "metrics.csv",
+			Data:           []string{"model=gpt4"},
+			MaxDepth:       3,
+			NewerThan:      "48h",
+		}, cfg)
+		assert.Contains(logs.String(), `unknown config key "colour"`)
+	})
+
+	t.Run("home fallback", func(t *testing.T) {
+		assert := assert.New(t)
+		writeFile(t, filepath.Join(home, ".config", "vibe", "config.toml"), `layout = "home-layout"`)
+		defer os.RemoveAll(filepath.Join(home, ".config"))
+
+		cfg, err := LoadConfig(t.TempDir())
+		assert.NoError(err)
+		assert.Equal("home-layout", cfg.Layout)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		assert := assert.New(t)
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, "vibe.toml"), `newer_than = "two days"`)
+		_, err := LoadConfig(root)
+		assert.Error(err)
+
+		writeFile(t, filepath.Join(root, "vibe.toml"), `layout = `)
+		_, err = LoadConfig(root)
+		assert.Error(err)
+	})
+}
+
+func TestConfigApply(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := Config{
+		TokenEstimator: "tiktoken",
+		Layout:         "files",
+		Select:         ".go$",
+		Data:           []string{"a=1"},
+		MaxTokens:      1000,
+		NewerThan:      "1h",
+	}
+
+	merged := cfg.Apply(OutCmd{Layout: []string{"cli-layout"}, Template: "task.md"})
+	assert.Equal("tiktoken", merged.TokenEstimator)
+	assert.Equal([]string{"cli-layout"}, merged.Layout)
+	assert.Equal("files", merged.Defaults.Layout)
+	assert.Equal("", merged.Select)
+	assert.Equal(".go$", merged.Defaults.Select)
+	assert.Equal([]string{"a=1"}, merged.Data)
+	assert.Equal(1000, merged.MaxTokens)
+	assert.Equal(time.Hour, *merged.NewerThan)
+	assert.Equal("task.md", merged.Template)
+
+	window := 5 * time.Minute
+	merged = cfg.Apply(OutCmd{Data: []string{"b=2"}, MaxTokens: 10, NewerThan: &window})
+	assert.Equal(0, len(merged.Layout))
+	assert.Equal("files", merged.Defaults.Layout)
+	assert.Equal([]string{"b=2"}, merged.Data)
+	assert.Equal(10, merged.MaxTokens)
+	assert.Equal(window, *merged.NewerThan)
+}
+
+func TestConfigApplyProfile(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(root, "vibe.toml"), []byte(`
+token_estimator = "simple"
+layout = "files"
+max_tokens = 5000
+
+[profiles.go-review]
+layout = "coder"
+select = "*.go;*.md"
+token_estimator = "tiktoken"
+`), 0644))
+
+	cfg, err := LoadConfig(root)
+	assert.NoError(err)
+
+	// CLI > profile > top-level config
+	merged, err := cfg.ApplyProfile(OutCmd{Profile: "go-review", Select: "cmd/"})
+	assert.NoError(err)
+	assert.Equal("coder", merged.Defaults.Layout)
+	assert.Equal("cmd/", merged.Select)
+	assert.Equal("*.go;*.md", merged.Defaults.Select)
+	assert.Equal("tiktoken", merged.TokenEstimator)
+	assert.Equal(5000, merged.MaxTokens)
+
+	// Without --profile the profiles are ignored
+	merged, err = cfg.ApplyProfile(OutCmd{})
+	assert.NoError(err)
+	assert.Equal("files", merged.Defaults.Layout)
+	assert.Equal("", merged.Defaults.Select)
+
+	_, err = cfg.ApplyProfile(OutCmd{Profile: "missing"})
+	assert.ErrorContains(err, `unknown profile "missing"`)
+
+	assert.NoError(os.WriteFile(filepath.Join(root, "vibe.toml"), []byte(`
+[profiles.recent]
+newer_than = "yesterday"
+`), 0644))
+	_, err = LoadConfig(root)
+	assert.ErrorContains(err, "profile recent: newer_than")
+}
+
+func TestConfigApplySavedProfile(t *testing.T) {
+	assert := assert.New(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	assert.NoError(os.MkdirAll(filepath.Join(home, ".vibe"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(home, ".vibe", "profiles.toml"), []byte(`
+[profiles.go-src]
+select = "*.go\n!*_test.go"
+
+[profiles.go-review]
+select = "saved/"
+`), 0644))
+
+	cfg := Config{
+		Layout:   "files",
+		Profiles: map[string]Config{"go-review": {Select: "*.go;*.md"}},
+	}
+
+	merged, err := cfg.ApplyProfile(OutCmd{Profile: "go-src"})
+	assert.NoError(err)
+	assert.Equal("*.go\n!*_test.go", merged.Defaults.Select)
+	assert.Equal("files", merged.Defaults.Layout)
+
+	// --select on the command line wins over the saved pattern
+	merged, err = cfg.ApplyProfile(OutCmd{Profile: "go-src", Select: "cmd/"})
+	assert.NoError(err)
+	assert.Equal("cmd/", merged.Select)
+
+	// vibe.toml profiles take precedence over saved ones
+	merged, err = cfg.ApplyProfile(OutCmd{Profile: "go-review"})
+	assert.NoError(err)
+	assert.Equal("*.go;*.md", merged.Defaults.Select)
+
+	// profile save writes to the same store
+	runner, err := NewProfileRunner(ProfileCmd{Save: &ProfileSaveCmd{Name: "docs", Select: "*.md"}})
+	assert.NoError(err)
+	runner.Output = io.Discard
+	assert.NoError(runner.Run())
+
+	merged, err = cfg.ApplyProfile(OutCmd{Profile: "docs"})
+	assert.NoError(err)
+	assert.Equal("*.md", merged.Defaults.Select)
+}
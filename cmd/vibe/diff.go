@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hayeah/fork2/render"
+)
+
+// DiffCmd defines the command-line arguments for the diff subcommand
+type DiffCmd struct {
+	Output  string   `arg:"-o,--output" help:"Output destination: '-' for stdout; file path to write; if not set, copy to clipboard"`
+	Content []string `arg:"-c,--content,separate" help:"Content source specifications: '-' for stdin, file paths, URLs, or literals (repeatable)"`
+	Root    string   `arg:"-r,--root" help:"Path to repo root (default: .)"`
+	Base    string   `arg:"positional" default:"main" help:"Git ref to diff against (default: main)"`
+}
+
+// DiffRunner renders a review prompt for the changes against a base ref.
+// It selects the changed files and hands off to the out pipeline with the
+// builtin diff template, which exposes the diff text as .GitDiff.
+type DiffRunner struct {
+	Args     DiffCmd
+	RootPath string
+}
+
+// NewDiffRunner creates and initializes a new DiffRunner
+func NewDiffRunner(cmd DiffCmd, root string) (*DiffRunner, error) {
+	if cmd.Root != "" {
+		root = cmd.Root
+	}
+	if cmd.Base == "" {
+		cmd.Base = "main"
+	}
+	return &DiffRunner{Args: cmd, RootPath: root}, nil
+}
+
+// Run executes the diff subcommand
+func (r *DiffRunner) Run() error {
+	loader := &render.GitDiffLoader{Rev: r.Args.Base, Dir: r.RootPath}
+	diff, err := loader.Load(context.Background())
+	if err != nil {
+		return err
+	}
+
+	paths := diffPaths(diff)
+	if len(paths) == 0 {
+		return fmt.Errorf("no changes against %s", r.Args.Base)
+	}
+
+	cfg, err := LoadConfig(r.RootPath)
+	if err != nil {
+		return err
+	}
+	outRunner, err := NewAskRunner(cfg.Apply(OutCmd{
+		Output:   r.Args.Output,
+		Content:  r.Args.Content,
+		Root:     r.RootPath,
+		Select:   diffSelectPattern(paths),
+		Template: "diff",
+		GitDiff:  diff,
+	}))
+	if err != nil {
+		return err
+	}
+	return outRunner.Run()
+}
+
+// diffPaths returns the sorted paths of the files present after a unified
+// diff is applied. Deleted files are left out, since there is nothing left
+// to select.
+func diffPaths(diff string) []string {
+	seen := map[string]bool{}
+	var (
+		cur     string
+		deleted bool
+		inHunk  bool // hunk lines may themselves start with "+++ "
+	)
+	flush := func() {
+		if cur != "" && !deleted {
+			seen[cur] = true
+		}
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			cur, deleted, inHunk = "", false, false
+			// header is "diff --git a/<old> b/<new>"; binary changes and pure
+			// renames have no +++ line, so it is the only source of the path
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				cur = line[i+len(" b/"):]
+			}
+		case inHunk:
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "deleted file mode"):
+			deleted = true
+		case strings.HasPrefix(line, "rename to "):
+			cur = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "+++ b/"):
+			// git ends the line with a tab when the path contains a space
+			cur = strings.TrimSuffix(strings.TrimPrefix(line, "+++ b/"), "\t")
+		}
+	}
+	flush()
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// diffSelectPattern builds a --select pattern that matches exactly paths,
+// one "=path" line per file so that spaces and glob characters are literal.
+func diffSelectPattern(paths []string) string {
+	lines := make([]string, len(paths))
+	for i, p := range paths {
+		lines[i] = "=" + p
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+// createDiffProject commits a small repo and then edits, adds and deletes a
+// file in the working tree.
+func createDiffProject(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VIBE_PROMPTS", "")
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	git("init", "-q")
+	write("changed.go", "package main\n")
+	write("same.go", "package same\n")
+	write("gone.go", "package gone\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	write("changed.go", "package main\n\nfunc changed() {}\n")
+	write("added.go", "package added\n")
+	write("my [draft].go", "package draft\n")
+	git("add", "added.go", "my [draft].go")
+	if err := os.Remove(filepath.Join(dir, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDiffRunner(t *testing.T) {
+	assert := assert.New(t)
+	dir := createDiffProject(t)
+	output := filepath.Join(t.TempDir(), "out.md")
+
+	runner, err := NewDiffRunner(DiffCmd{Base: "HEAD", Root: dir, Output: output}, ".")
+	assert.NoError(err)
+	assert.NoError(runner.Run())
+
+	got, err := os.ReadFile(output)
+	assert.NoError(err)
+	out := string(got)
+
+	assert.Contains(out, "## Changes Under Review")
+	assert.Contains(out, "+func changed() {}")
+	assert.Contains(out, "<!-- Read File: changed.go -->")
+	assert.Contains(out, "<!-- Read File: added.go -->")
+	assert.Contains(out, "<!-- Read File: my [draft].go -->")
+	assert.NotContains(out, "<!-- Read File: same.go -->")
+	assert.NotContains(out, "<!-- Read File: gone.go -->")
+}
+
+func TestDiffRunner_NoChanges(t *testing.T) {
+	assert := assert.New(t)
+	dir := createDiffProject(t)
+
+	cmd := exec.Command("git", "reset", "-q", "--hard")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git reset: %v\n%s", err, out)
+	}
+
+	runner, err := NewDiffRunner(DiffCmd{Base: "HEAD", Root: dir}, ".")
+	assert.NoError(err)
+	assert.ErrorContains(runner.Run(), "no changes against HEAD")
+}
+
+func TestDiffPaths(t *testing.T) {
+	assert := assert.New(t)
+
+	diff := `diff --git a/cmd/main.go b/cmd/main.go
+index 1111111..2222222 100644
+--- a/cmd/main.go
++++ b/cmd/main.go
+@@ -1 +1,2 @@
+ package main
++++ b/not/a/header.go
+diff --git a/old.go b/new.go
+similarity index 100%
+rename from old.go
+rename to new.go
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..4444444
+Binary files /dev/null and b/logo.png differ
+diff --git a/my file.go b/my file.go
+--- a/my file.go	
++++ b/my file.go	
+@@ -1 +1 @@
+-package a
++package b
+`
+	assert.Equal([]string{"cmd/main.go", "logo.png", "my file.go", "new.go"}, diffPaths(diff))
+	assert.Equal("=a.go\n=b/c.go\n=my [draft].go", diffSelectPattern([]string{"a.go", "b/c.go", "my [draft].go"}))
+}
+
+func TestOutRunner_GitContentUnderRoot(t *testing.T) {
+	assert := assert.New(t)
+	dir := createDiffProject(t)
+	templates, err := filepath.Abs("testdata/templates")
+	assert.NoError(err)
+	output := filepath.Join(t.TempDir(), "out.md")
+
+	runner, err := NewAskRunner(OutCmd{
+		Root:           dir,
+		TemplatePaths:  []string{templates},
+		Template:       "content_test.md",
+		Content:        []string{"gitdiff:HEAD", "git:HEAD:gone.go"},
+		Output:         output,
+		TokenEstimator: "simple",
+	})
+	assert.NoError(err)
+	assert.NoError(runner.Run())
+
+	got, err := os.ReadFile(output)
+	assert.NoError(err)
+	assert.Contains(string(got), "+func changed() {}")
+	assert.Contains(string(got), "package gone")
+}
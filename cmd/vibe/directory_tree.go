@@ -1,59 +1,322 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	selection "github.com/hayeah/fork2/internal/selection"
 	setpkg "github.com/hayeah/fork2/internal/set"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/hayeah/fork2/ignore"
 )
 
 // DirectoryTree holds directory listing info.
 type DirectoryTree struct {
-	RootPath string
-	dirItems func() ([]item, error) // Memoized function for walkItems
-	fsys     fs.FS                  // File system to use for file operations
+	RootPath  string
+	MaxDepth  int                    // Maximum directory depth to walk, 0 = unlimited
+	Parallel  bool                   // Read directories concurrently
+	NewerThan *time.Duration         // Only include files modified within this window, nil = all
+	dirItems  func() ([]item, error) // Memoized function for walkItems
+	fsys      fs.FS                  // File system to use for file operations
+
+	ExtraIgnorePatterns []string // .gitignore-style patterns applied on top of the ignore files
+
+	TokenEstimator TokenEstimator // fills token_count in MarshalJSON; nil leaves it at 0
+
+	sparsePaths []string // set by NewDirectoryTreeSparse; the files to list instead of walking
+
+	itemsMu   sync.Mutex
+	itemsOnce func() ([]item, error) // Current memoized walk, replaced by invalidate
+}
+
+// DirectoryTreeOptions configures how a DirectoryTree walks its root.
+type DirectoryTreeOptions struct {
+	MaxDepth  int            // Maximum directory depth to walk, 0 = unlimited
+	Parallel  bool           // Read directories concurrently; results are sorted into walk order
+	NewerThan *time.Duration // Only include files modified within this window; directories are always kept
+	// ExtraIgnorePatterns are added to the .gitignore/.vibeIgnore patterns
+	ExtraIgnorePatterns []string
 }
 
 // NewDirectoryTree constructs a DirectoryTree for the given rootPath, but does not walk the directory yet.
 func NewDirectoryTree(rootPath string) *DirectoryTree {
+	return NewDirectoryTreeWithOptions(rootPath, DirectoryTreeOptions{})
+}
+
+// NewDirectoryTreeWithOptions constructs a DirectoryTree for the given rootPath
+// configured by opts, but does not walk the directory yet.
+func NewDirectoryTreeWithOptions(rootPath string, opts DirectoryTreeOptions) *DirectoryTree {
 	dt := &DirectoryTree{
-		RootPath: rootPath,
-		fsys:     os.DirFS(rootPath),
+		RootPath:  rootPath,
+		MaxDepth:  opts.MaxDepth,
+		Parallel:  opts.Parallel,
+		NewerThan: opts.NewerThan,
+		fsys:      os.DirFS(rootPath),
+
+		ExtraIgnorePatterns: opts.ExtraIgnorePatterns,
 	}
-	dt.dirItems = sync.OnceValues(dt.dirItemsImpl)
+	dt.itemsOnce = sync.OnceValues(dt.dirItemsImpl)
+	dt.dirItems = dt.cachedDirItems
 	return dt
 }
 
+// NewDirectoryTreeSparse constructs a DirectoryTree that lists only paths,
+// relative to rootPath, and their parent directories. The filesystem is not
+// walked and ignore files are not applied; files are still read from
+// rootPath when their content is needed.
+func NewDirectoryTreeSparse(rootPath string, paths []string) *DirectoryTree {
+	dt := NewDirectoryTree(rootPath)
+	dt.sparsePaths = append([]string{}, paths...)
+	return dt
+}
+
+// cachedDirItems returns the memoized walk result.
+func (dt *DirectoryTree) cachedDirItems() ([]item, error) {
+	dt.itemsMu.Lock()
+	once := dt.itemsOnce
+	dt.itemsMu.Unlock()
+	return once()
+}
+
+// invalidate drops the memoized walk so the next dirItems call walks again.
+func (dt *DirectoryTree) invalidate() {
+	dt.itemsMu.Lock()
+	dt.itemsOnce = sync.OnceValues(dt.dirItemsImpl)
+	dt.itemsMu.Unlock()
+}
+
+// newIgnore loads the ignore files in the root and adds ExtraIgnorePatterns.
+func (dt *DirectoryTree) newIgnore() (*ignore.Ignore, error) {
+	ig, err := ignore.NewIgnore(dt.RootPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range dt.ExtraIgnorePatterns {
+		if err := ig.AddPattern(p); err != nil {
+			return nil, err
+		}
+	}
+	return ig, nil
+}
+
 // dirItemsImpl is the actual implementation that walks the directory tree.
 func (dt *DirectoryTree) dirItemsImpl() ([]item, error) {
+	if dt.sparsePaths != nil {
+		return dt.sparseItems(), nil
+	}
+
 	var items []item
 
-	ig, err := ignore.NewIgnore(dt.RootPath)
+	ig, err := dt.newIgnore()
 	if err != nil {
 		return nil, err
 	}
+	if dt.Parallel {
+		return dt.dirItemsParallel(ig)
+	}
+
+	cutoff := dt.cutoff()
 	err = ig.WalkDir(dt.RootPath, func(path string, d os.DirEntry, isDir bool) error {
 		relPath, err := filepath.Rel(dt.RootPath, path)
 		if err != nil {
 			return err
 		}
+		if !isDir {
+			if old, err := modifiedBefore(path, cutoff); err != nil || old {
+				return err
+			}
+		}
 		items = append(items, item{
 			Path:       relPath,
 			IsDir:      isDir,
 			TokenCount: 0,
 		})
+
+		// Don't descend into directories at the depth limit
+		if isDir && dt.MaxDepth > 0 && pathDepth(relPath) >= dt.MaxDepth {
+			return filepath.SkipDir
+		}
 		return nil
 	})
 	return items, err
 }
 
+// sparseItems returns the items of a sparse tree in walk order: the root,
+// the given files, and every directory that contains one of them.
+func (dt *DirectoryTree) sparseItems() []item {
+	seen := map[string]bool{".": true}
+	items := []item{{Path: ".", IsDir: true}}
+	for _, p := range dt.sparsePaths {
+		p = filepath.Clean(filepath.FromSlash(p))
+		if p == "." || seen[p] {
+			continue
+		}
+		seen[p] = true
+		items = append(items, item{Path: p})
+
+		for dir := filepath.Dir(p); dir != "." && !seen[dir]; dir = filepath.Dir(dir) {
+			seen[dir] = true
+			items = append(items, item{Path: dir, IsDir: true})
+		}
+	}
+	sortWalkOrder(items)
+	return items
+}
+
+// dirItemsParallel walks the tree with at most runtime.NumCPU() directories
+// being read at once. Items are gathered by a collector goroutine and sorted
+// into the same order the sequential walk produces.
+func (dt *DirectoryTree) dirItemsParallel(ig *ignore.Ignore) ([]item, error) {
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, runtime.NumCPU())
+		results = make(chan []item)
+		errOnce sync.Once
+		walkErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() { walkErr = err })
+	}
+	cutoff := dt.cutoff()
+
+	var walk func(relDir string)
+	walk = func(relDir string) {
+		defer wg.Done()
+
+		sem <- struct{}{}
+		entries, err := os.ReadDir(filepath.Join(dt.RootPath, relDir))
+		<-sem
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		batch := make([]item, 0, len(entries))
+		for _, e := range entries {
+			relPath := filepath.Join(relDir, e.Name())
+			isDir := e.IsDir()
+			ignored, err := ig.IsIgnored(filepath.Join(dt.RootPath, relPath), isDir)
+			if err != nil {
+				fail(err)
+				return
+			}
+			if ignored {
+				continue
+			}
+			if !isDir {
+				old, err := modifiedBefore(filepath.Join(dt.RootPath, relPath), cutoff)
+				if err != nil {
+					fail(err)
+					return
+				}
+				if old {
+					continue
+				}
+			}
+
+			batch = append(batch, item{Path: relPath, IsDir: isDir})
+			if isDir && (dt.MaxDepth <= 0 || pathDepth(relPath) < dt.MaxDepth) {
+				wg.Add(1)
+				go walk(relPath)
+			}
+		}
+		results <- batch
+	}
+
+	items := []item{{Path: ".", IsDir: true}}
+	collected := make(chan struct{})
+	go func() {
+		for batch := range results {
+			items = append(items, batch...)
+		}
+		close(collected)
+	}()
+
+	wg.Add(1)
+	go walk(".")
+	wg.Wait()
+	close(results)
+	<-collected
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	// The root stays first
+	sortWalkOrder(items[1:])
+	return items, nil
+}
+
+// sortWalkOrder sorts paths the way filepath.WalkDir visits them: each
+// directory before its contents, siblings in lexical order.
+func sortWalkOrder(items []item) {
+	slices.SortFunc(items, func(a, b item) int {
+		return compareWalkOrder(a.Path, b.Path)
+	})
+}
+
+// compareWalkOrder compares paths component by component, treating the
+// separator as lower than any other byte so "a/b" sorts before "a-b".
+func compareWalkOrder(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := a[i], b[i]
+		switch {
+		case ca == cb:
+			continue
+		case ca == filepath.Separator:
+			return -1
+		case cb == filepath.Separator:
+			return 1
+		default:
+			return cmp.Compare(ca, cb)
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// cutoff returns the oldest modification time a file may have to be
+// included, or the zero time if NewerThan is unset.
+func (dt *DirectoryTree) cutoff() time.Time {
+	if dt.NewerThan == nil {
+		return time.Time{}
+	}
+	return time.Now().Add(-*dt.NewerThan)
+}
+
+// modifiedBefore reports whether path was last modified before cutoff.
+// A zero cutoff never excludes anything.
+func modifiedBefore(path string, cutoff time.Time) (bool, error) {
+	if cutoff.IsZero() {
+		return false, nil
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	return info.ModTime().Before(cutoff), nil
+}
+
+// pathDepth returns the number of components in relPath; "." has depth 0.
+func pathDepth(relPath string) int {
+	if relPath == "." {
+		return 0
+	}
+	return strings.Count(relPath, string(filepath.Separator)) + 1
+}
+
 // SelectAllFiles returns all non-directory file paths
 func (dt *DirectoryTree) SelectAllFiles() []string {
 	items, err := dt.dirItems()
@@ -70,28 +333,50 @@ func (dt *DirectoryTree) SelectAllFiles() []string {
 }
 
 // SelectFiles returns file selections for the given select string (no memoization).
-func (dt *DirectoryTree) SelectFiles(selectString string) ([]selection.FileSelection, error) {
+// Paths matched by excludeString are removed after the select matchers run.
+func (dt *DirectoryTree) SelectFiles(selectString, excludeString string) ([]selection.FileSelection, error) {
 	set := selection.NewFileSelectionSet()
 	if selectString != "" {
-		matchers, err := selection.ParseMatchersFromString(selectString)
+		allPaths := dt.SelectAllFiles()
+		included, err := dt.matchSelections(selectString, allPaths)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse select string: %w", err)
 		}
+		set = included
 
-		allPaths := dt.SelectAllFiles()
-		for _, matcher := range matchers {
-			matchedPaths, err := matcher.Match(allPaths)
+		if excludeString != "" {
+			excluded, err := dt.matchSelections(excludeString, allPaths)
 			if err != nil {
-				return nil, err
-			}
-			for _, path := range matchedPaths {
-				set.Add(selection.NewFileSelection(dt.fsys, path, nil))
+				return nil, fmt.Errorf("failed to parse exclude string: %w", err)
 			}
+			set = set.Difference(excluded)
 		}
 	}
 	return set.Values(), nil
 }
 
+// matchSelections runs every matcher in pattern over paths and collects the
+// matched files into a set.
+func (dt *DirectoryTree) matchSelections(pattern string, paths []string) (*selection.FileSelectionSet, error) {
+	matchers, err := selection.ParseMatchersFromString(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matchers = selection.RootMatchers(matchers, dt.RootPath, dt.fsys)
+
+	set := selection.NewFileSelectionSet()
+	for _, matcher := range matchers {
+		matchedPaths, err := matcher.Match(paths)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matchedPaths {
+			set.Add(selection.NewFileSelection(dt.fsys, path, nil))
+		}
+	}
+	return set, nil
+}
+
 // Filter returns the minimal set of items that contains every path
 // matched by pattern plus all their ancestor directories.
 func (dt *DirectoryTree) Filter(pattern string) ([]item, error) {
@@ -104,6 +389,7 @@ func (dt *DirectoryTree) Filter(pattern string) ([]item, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pattern: %w", err)
 	}
+	matchers = selection.RootMatchers(matchers, dt.RootPath, dt.fsys)
 
 	// 2. collect all non-dir file paths, run every matcher, build a set.
 	allItems, err := dt.dirItems()
@@ -158,6 +444,171 @@ func (dt *DirectoryTree) Filter(pattern string) ([]item, error) {
 	return filteredItems, nil
 }
 
+// dirTreeWatchDebounce is how long Watch collects changes before sending them.
+var dirTreeWatchDebounce = 200 * time.Millisecond
+
+// Watch watches RootPath recursively and sends the paths (relative to
+// RootPath, sorted) that changed during each debounce window. Ignored files
+// and directories are not watched; directories created later are picked up
+// as they appear. The memoized walk is invalidated before each send.
+//
+// The channel is closed when ctx is done.
+func (dt *DirectoryTree) Watch(ctx context.Context) (<-chan []string, error) {
+	ig, err := dt.newIgnore()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating watcher: %w", err)
+	}
+	if err := watchDirs(w, ig, dt.RootPath); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+		defer w.Close()
+
+		timer := time.NewTimer(dirTreeWatchDebounce)
+		timer.Stop()
+		defer timer.Stop()
+
+		pending := setpkg.NewSet[string]()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if ev.Op == fsnotify.Chmod {
+					continue
+				}
+
+				fi, statErr := os.Stat(ev.Name)
+				isDir := statErr == nil && fi.IsDir()
+				if ignored, err := ig.IsIgnored(ev.Name, isDir); err != nil || ignored {
+					continue
+				}
+				if isDir && ev.Has(fsnotify.Create) {
+					if err := watchDirs(w, ig, ev.Name); err != nil {
+						log.Printf("watch error: %v", err)
+					}
+				}
+
+				relPath, err := filepath.Rel(dt.RootPath, ev.Name)
+				if err != nil {
+					continue
+				}
+				pending.Add(relPath)
+				timer.Reset(dirTreeWatchDebounce)
+
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("watch error: %v", err)
+
+			case <-timer.C:
+				dt.invalidate()
+				paths := pending.Values()
+				sort.Strings(paths)
+				pending.Clear()
+
+				select {
+				case out <- paths:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// watchDirs adds root and every directory below it that isn't ignored to w.
+func watchDirs(w *fsnotify.Watcher, ig *ignore.Ignore, root string) error {
+	return ig.WalkDir(root, func(path string, d os.DirEntry, isDir bool) error {
+		if !isDir {
+			return nil
+		}
+		if err := w.Add(path); err != nil {
+			return fmt.Errorf("error watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// sumTokenCounts sets each directory's token count to the sum of its
+// children's and returns the count of n.
+func sumTokenCounts(n *treeNodeJSON) int {
+	if !n.IsDir {
+		return n.TokenCount
+	}
+	n.TokenCount = 0
+	for _, c := range n.Children {
+		n.TokenCount += sumTokenCounts(c)
+	}
+	return n.TokenCount
+}
+
+// treeNodeJSON is the JSON form of a DirectoryTree node.
+type treeNodeJSON struct {
+	Name       string          `json:"name"`
+	Path       string          `json:"path"`
+	IsDir      bool            `json:"is_dir"`
+	TokenCount int             `json:"token_count"`
+	Children   []*treeNodeJSON `json:"children,omitempty"`
+}
+
+// MarshalJSON serializes the tree as nested nodes. The root node carries the
+// absolute RootPath; every other node's path is relative to it. File token
+// counts come from TokenEstimator, and a directory's is the sum of its
+// children's.
+func (dt *DirectoryTree) MarshalJSON() ([]byte, error) {
+	items, err := dt.dirItems()
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(dt.RootPath)
+	if err != nil {
+		absPath = dt.RootPath
+	}
+	root := &treeNodeJSON{Name: filepath.Base(absPath), Path: absPath, IsDir: true}
+
+	nodes := map[string]*treeNodeJSON{".": root}
+	for _, it := range items {
+		if it.Path == "" || it.Path == "." {
+			continue
+		}
+		node := &treeNodeJSON{
+			Name:       filepath.Base(it.Path),
+			Path:       filepath.ToSlash(it.Path),
+			IsDir:      it.IsDir,
+			TokenCount: it.TokenCount,
+		}
+		if !it.IsDir && node.TokenCount == 0 && dt.TokenEstimator != nil {
+			// unreadable files count as 0
+			node.TokenCount, _ = dt.TokenEstimator(dt.fsys, filepath.ToSlash(it.Path))
+		}
+		nodes[it.Path] = node
+		if parent, ok := nodes[filepath.Dir(it.Path)]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	sumTokenCounts(root)
+
+	return json.Marshal(root)
+}
+
 // GenerateDirectoryTree writes a tree-like directory structure to w based on dt.
 func (dt *DirectoryTree) GenerateDirectoryTree(w io.Writer, pattern string) error {
 	diagram, err := NewDirectoryTreeDiagram(dt, pattern)
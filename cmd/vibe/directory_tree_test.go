@@ -2,12 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	setpkg "github.com/hayeah/fork2/internal/set"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -164,3 +167,333 @@ func TestDirectoryTree_FilterEmptyPattern(t *testing.T) {
 	// With empty pattern, should return all items
 	assert.Equal(allItems, filteredItems, "Empty pattern should return all items")
 }
+
+func TestDirectoryTree_MaxDepth(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"top.txt":           "top",
+		"a/mid.txt":         "mid",
+		"a/b/deep.txt":      "deep",
+		"a/b/c/deepest.txt": "deepest",
+	})
+	assert.NoError(err)
+
+	paths := func(dt *DirectoryTree) []string {
+		items, err := dt.dirItems()
+		assert.NoError(err)
+		var out []string
+		for _, it := range items {
+			out = append(out, it.Path)
+		}
+		return out
+	}
+
+	dt := NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{MaxDepth: 1})
+	assert.ElementsMatch([]string{".", "a", "top.txt"}, paths(dt))
+	assert.ElementsMatch([]string{"top.txt"}, dt.SelectAllFiles())
+
+	dt = NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{MaxDepth: 2})
+	assert.ElementsMatch([]string{".", "a", "a/b", "a/mid.txt", "top.txt"}, paths(dt))
+
+	dt = NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{})
+	assert.Len(dt.SelectAllFiles(), 4)
+}
+
+func TestDirectoryTree_Parallel(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		".gitignore":       "ignored/\n*.log\n",
+		"a.txt":            "a",
+		"a/b.txt":          "b",
+		"a/b/c.txt":        "c",
+		"a-b/d.txt":        "d",
+		"a.d/e.txt":        "e",
+		"debug.log":        "log",
+		"ignored/skip.txt": "skip",
+		"z/y/x/w/deep.txt": "deep",
+	})
+	assert.NoError(err)
+
+	for _, depth := range []int{0, 1, 2} {
+		seq := NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{MaxDepth: depth})
+		par := NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{MaxDepth: depth, Parallel: true})
+
+		want, err := seq.dirItems()
+		assert.NoError(err)
+		got, err := par.dirItems()
+		assert.NoError(err)
+		assert.Equal(want, got, "max depth %d", depth)
+	}
+}
+
+func BenchmarkDirectoryTree_Walk(b *testing.B) {
+	root := b.TempDir()
+	for d := 0; d < 100; d++ {
+		dir := filepath.Join(root, fmt.Sprintf("dir%03d", d), "sub")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			b.Fatal(err)
+		}
+		for f := 0; f < 100; f++ {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%03d.txt", f)), nil, 0644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	for _, parallel := range []bool{false, true} {
+		b.Run(fmt.Sprintf("parallel=%v", parallel), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dt := NewDirectoryTreeWithOptions(root, DirectoryTreeOptions{Parallel: parallel})
+				if _, err := dt.dirItems(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDirectoryTree_NewerThan(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"fresh.txt":     "fresh",
+		"stale.txt":     "stale",
+		"old/stale.txt": "stale",
+		"old/fresh.txt": "fresh",
+		"ancient/a.txt": "ancient",
+	})
+	assert.NoError(err)
+
+	now := time.Now()
+	setAge := func(rel string, age time.Duration) {
+		mt := now.Add(-age)
+		assert.NoError(os.Chtimes(filepath.Join(tempDir, rel), mt, mt))
+	}
+	setAge("fresh.txt", time.Hour)
+	setAge("old/fresh.txt", 47*time.Hour)
+	setAge("stale.txt", 72*time.Hour)
+	setAge("old/stale.txt", 49*time.Hour)
+	setAge("ancient/a.txt", 365*24*time.Hour)
+	setAge("ancient", 365*24*time.Hour)
+
+	window := 48 * time.Hour
+	for _, parallel := range []bool{false, true} {
+		dt := NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{NewerThan: &window, Parallel: parallel})
+		assert.ElementsMatch([]string{"fresh.txt", "old/fresh.txt"}, dt.SelectAllFiles())
+
+		// directories are kept even when all their files are filtered out
+		items, err := dt.dirItems()
+		assert.NoError(err)
+		var dirs []string
+		for _, it := range items {
+			if it.IsDir {
+				dirs = append(dirs, it.Path)
+			}
+		}
+		assert.ElementsMatch([]string{".", "ancient", "old"}, dirs)
+	}
+
+	assert.Len(NewDirectoryTree(tempDir).SelectAllFiles(), 5)
+}
+
+func TestDirectoryTree_Watch(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		".gitignore": "*.log\n",
+		"a.txt":      "a",
+		"sub/b.txt":  "b",
+	})
+	assert.NoError(err)
+
+	dt := NewDirectoryTree(tempDir)
+	assert.Len(dt.SelectAllFiles(), 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := dt.Watch(ctx)
+	assert.NoError(err)
+
+	expect := func(want ...string) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			assert.Equal(want, got)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no change reported, want %v", want)
+		}
+	}
+
+	assert.NoError(os.WriteFile(filepath.Join(tempDir, "sub", "b.txt"), []byte("changed"), 0644))
+	assert.NoError(os.WriteFile(filepath.Join(tempDir, "ignored.log"), []byte("log"), 0644))
+	expect(filepath.Join("sub", "b.txt"))
+
+	// new directories are watched, and the memoized walk sees new files
+	assert.NoError(os.MkdirAll(filepath.Join(tempDir, "new"), 0755))
+	expect("new")
+	assert.NoError(os.WriteFile(filepath.Join(tempDir, "new", "c.txt"), []byte("c"), 0644))
+	expect(filepath.Join("new", "c.txt"))
+	assert.Contains(dt.SelectAllFiles(), filepath.Join("new", "c.txt"))
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		assert.False(ok, "channel should be closed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestDirectoryTree_MarshalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"a.txt":         "a",
+		"sub/b.txt":     "b",
+		"sub/deep/c.go": "package deep\n",
+	})
+	assert.NoError(err)
+
+	dt := NewDirectoryTree(tempDir)
+	dt.TokenEstimator = estimateTokenCountSimple
+	b, err := json.Marshal(dt)
+	assert.NoError(err)
+
+	var root treeNodeJSON
+	assert.NoError(json.Unmarshal(b, &root))
+
+	assert.Equal(tempDir, root.Path)
+	assert.Equal(filepath.Base(tempDir), root.Name)
+	assert.True(root.IsDir)
+
+	// flatten to "path(dir?) -> children" to compare structure
+	structure := map[string][]string{}
+	var walk func(n *treeNodeJSON)
+	walk = func(n *treeNodeJSON) {
+		var children []string
+		for _, c := range n.Children {
+			assert.Equal(filepath.Base(c.Path), c.Name)
+			children = append(children, c.Path)
+			walk(c)
+		}
+		if n.IsDir {
+			structure[n.Path] = children
+		}
+	}
+	walk(&root)
+
+	assert.Equal(map[string][]string{
+		tempDir:    {"a.txt", "sub"},
+		"sub":      {"sub/b.txt", "sub/deep"},
+		"sub/deep": {"sub/deep/c.go"},
+	}, structure)
+
+	// file counts come from the estimator, directories sum their children
+	tokens := map[string]int{}
+	walk = func(n *treeNodeJSON) {
+		tokens[n.Path] = n.TokenCount
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(&root)
+	assert.Equal(3, tokens["sub/deep/c.go"])
+	assert.Equal(3, tokens["sub"])
+	assert.Equal(3, tokens[tempDir])
+	assert.Equal(0, tokens["a.txt"])
+
+	// marshalling the decoded tree again yields the same JSON
+	again, err := json.Marshal(&root)
+	assert.NoError(err)
+	assert.JSONEq(string(b), string(again))
+}
+
+func TestDirectoryTree_SelectFilesExclude(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"main.go":             "package main",
+		"main_test.go":        "package main",
+		"src/process.go":      "package src",
+		"src/process_test.go": "package src",
+		"README.md":           "readme",
+	})
+	assert.NoError(err)
+
+	dt := NewDirectoryTree(tempDir)
+
+	paths := func(pattern, exclude string) []string {
+		sels, err := dt.SelectFiles(pattern, exclude)
+		assert.NoError(err)
+		var out []string
+		for _, s := range sels {
+			out = append(out, s.Path)
+		}
+		return out
+	}
+
+	assert.Equal([]string{"main.go", "src/process.go"}, paths("*.go", "*_test.go"))
+	assert.Equal([]string{"main.go", "main_test.go", "src/process.go", "src/process_test.go"}, paths("*.go", ""))
+	assert.Equal([]string{"main.go", "main_test.go"}, paths("*.go", "src/**"))
+	assert.Empty(paths("", "*_test.go"))
+}
+
+func TestDirectoryTree_ExtraIgnorePatterns(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"main.go":          "package main",
+		"gen/generated.go": "package gen",
+		"notes.txt":        "notes",
+	})
+	assert.NoError(err)
+
+	dt := NewDirectoryTreeWithOptions(tempDir, DirectoryTreeOptions{ExtraIgnorePatterns: []string{"gen/", "*.txt"}})
+	assert.Equal([]string{"main.go"}, dt.SelectAllFiles())
+}
+
+func TestDirectoryTree_Sparse(t *testing.T) {
+	assert := assert.New(t)
+
+	// nothing exists on disk: a sparse tree never walks the root
+	dt := NewDirectoryTreeSparse(t.TempDir(), []string{
+		"hello/world/b.txt",
+		"./hello/a.txt",
+		"top.txt",
+		"hello/a.txt",
+	})
+
+	items, err := dt.dirItems()
+	assert.NoError(err)
+	var paths []string
+	for _, it := range items {
+		if it.IsDir {
+			paths = append(paths, it.Path+"/")
+		} else {
+			paths = append(paths, it.Path)
+		}
+	}
+	assert.Equal([]string{"./", "hello/", "hello/a.txt", "hello/world/", "hello/world/b.txt", "top.txt"}, paths)
+
+	assert.Equal([]string{"hello/a.txt", "hello/world/b.txt", "top.txt"}, dt.SelectAllFiles())
+
+	var buf bytes.Buffer
+	assert.NoError(dt.GenerateDirectoryTree(&buf, ""))
+	assert.Contains(buf.String(), strings.TrimSpace(`
+├── hello/
+│   ├── a.txt
+│   └── world/
+│       └── b.txt
+└── top.txt
+`))
+
+	// Ignore files do not apply
+	tempDir, err := createTestDirectory(t, map[string]string{
+		".gitignore": "*.log\n",
+		"debug.log":  "x",
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"debug.log"}, NewDirectoryTreeSparse(tempDir, []string{"debug.log"}).SelectAllFiles())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// editOutput writes out to a temp file, opens it for editing and returns the
+// file's contents once the editor exits.
+func editOutput(out []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "vibe-*.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %v", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.Write(out); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %v", err)
+	}
+
+	if err := openInEditor(path); err != nil {
+		return nil, err
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited output: %v", err)
+	}
+	return edited, nil
+}
+
+// openInEditor runs $EDITOR (or $VISUAL) on path and waits for it to exit.
+// Without either, the file is handed to the OS default application and the
+// user confirms with Enter once they have saved it.
+func openInEditor(path string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+
+	tty, in, out := openTerminal()
+	if tty != nil {
+		defer tty.Close()
+	}
+
+	if editor == "" {
+		if err := osOpen(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Opened %s, press Enter when done editing\n", path)
+		_, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	}
+
+	// the editor may carry flags, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = in
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %v", editor, err)
+	}
+	return nil
+}
+
+// openTerminal returns the controlling terminal for the editor to talk to,
+// since stdin may carry --content and stdout may carry the prompt. It falls
+// back to stdin and stderr when there is no terminal.
+func openTerminal() (*os.File, io.Reader, io.Writer) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, os.Stdin, os.Stderr
+	}
+	return tty, tty, tty
+}
+
+// osOpen hands path to the platform's default application without waiting.
+func osOpen(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hayeah/fork2/internal/metrics"
 	selection "github.com/hayeah/fork2/internal/selection"
@@ -15,11 +17,19 @@ type FileMap struct {
 	Files map[string]string
 }
 
+// FileMapOptions controls how FileMapWriter formats each file
+type FileMapOptions struct {
+	// CodeFences wraps each file's content in a Markdown code fence tagged
+	// with the language derived from its extension
+	CodeFences bool
+}
+
 // FileMapWriter handles writing file selections with optional metrics tracking
 type FileMapWriter struct {
 	fsys    fs.FS
 	baseDir string
 	metrics *metrics.OutputMetrics
+	opts    FileMapOptions
 }
 
 // IsBinaryFile checks if content is likely binary by sampling the first 100 runes
@@ -34,6 +44,46 @@ func NewWriteFileMap(fsys fs.FS, baseDir string, m *metrics.OutputMetrics) *File
 	}
 }
 
+// NewWriteFileMapWithOptions creates a FileMapWriter that formats files according to opts
+func NewWriteFileMapWithOptions(fsys fs.FS, baseDir string, m *metrics.OutputMetrics, opts FileMapOptions) *FileMapWriter {
+	w := NewWriteFileMap(fsys, baseDir, m)
+	w.opts = opts
+	return w
+}
+
+// WriteFileMap writes each of files, relative to rootPath or absolute, to w.
+// Content is streamed from each file as it is read.
+func WriteFileMap(w io.Writer, files []string, rootPath string) error {
+	return WriteFileMapWithOptions(w, files, rootPath, FileMapOptions{})
+}
+
+// WriteFileMapString is WriteFileMap returning the file map as a string.
+func WriteFileMapString(files []string, rootPath string) (string, error) {
+	var buf strings.Builder
+	if err := WriteFileMap(&buf, files, rootPath); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WriteFileMapWithOptions writes each of files, relative to root or absolute,
+// to w with a FileMapWriter formatting according to opts.
+func WriteFileMapWithOptions(w io.Writer, files []string, root string, opts FileMapOptions) error {
+	fsys := os.DirFS(root)
+	selections := make([]selection.FileSelection, 0, len(files))
+	for _, f := range files {
+		if filepath.IsAbs(f) {
+			rel, err := filepath.Rel(root, f)
+			if err != nil {
+				return err
+			}
+			f = rel
+		}
+		selections = append(selections, selection.NewFileSelection(fsys, filepath.ToSlash(f), nil))
+	}
+	return NewWriteFileMapWithOptions(fsys, root, nil, opts).Output(w, selections)
+}
+
 // Output writes file selections to the provided writer
 func (w *FileMapWriter) Output(out io.Writer, selections []selection.FileSelection) error {
 	for _, selection := range selections {
@@ -56,7 +106,12 @@ func (w *FileMapWriter) Output(out io.Writer, selections []selection.FileSelecti
 		}
 
 		// Stream file content directly to output and get byte count
-		bytesWritten, err := selection.Read(out)
+		var bytesWritten int64
+		if w.opts.CodeFences {
+			bytesWritten, err = selection.ReadFenced(out, ExtensionToLanguage(filepath.Ext(path)))
+		} else {
+			bytesWritten, err = selection.Read(out)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to write selected content from %s: %w", selection.Path, err)
 		}
@@ -69,3 +124,72 @@ func (w *FileMapWriter) Output(out io.Writer, selections []selection.FileSelecti
 
 	return nil
 }
+
+// extensionLanguages maps file extensions to Markdown code fence languages
+var extensionLanguages = map[string]string{
+	".bash":       "bash",
+	".c":          "c",
+	".cc":         "cpp",
+	".clj":        "clojure",
+	".cpp":        "cpp",
+	".cs":         "csharp",
+	".css":        "css",
+	".cxx":        "cpp",
+	".dart":       "dart",
+	".dockerfile": "dockerfile",
+	".ex":         "elixir",
+	".exs":        "elixir",
+	".fish":       "fish",
+	".go":         "go",
+	".graphql":    "graphql",
+	".h":          "c",
+	".hpp":        "cpp",
+	".hs":         "haskell",
+	".html":       "html",
+	".java":       "java",
+	".js":         "javascript",
+	".json":       "json",
+	".jsx":        "jsx",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".lua":        "lua",
+	".m":          "objectivec",
+	".md":         "markdown",
+	".mjs":        "javascript",
+	".ml":         "ocaml",
+	".php":        "php",
+	".pl":         "perl",
+	".proto":      "protobuf",
+	".ps1":        "powershell",
+	".py":         "python",
+	".r":          "r",
+	".rb":         "ruby",
+	".rs":         "rust",
+	".scala":      "scala",
+	".scss":       "scss",
+	".sh":         "bash",
+	".sql":        "sql",
+	".svelte":     "svelte",
+	".swift":      "swift",
+	".tf":         "hcl",
+	".toml":       "toml",
+	".ts":         "typescript",
+	".tsx":        "tsx",
+	".vue":        "vue",
+	".xml":        "xml",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".zig":        "zig",
+	".zsh":        "zsh",
+}
+
+// ExtensionToLanguage returns the code fence language for a file extension
+// such as ".go". Unknown extensions fall back to the extension without its
+// dot; an empty extension yields an empty language.
+func ExtensionToLanguage(ext string) string {
+	ext = strings.ToLower(ext)
+	if lang, ok := extensionLanguages[ext]; ok {
+		return lang
+	}
+	return strings.TrimPrefix(ext, ".")
+}
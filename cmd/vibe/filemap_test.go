@@ -50,3 +50,91 @@ func TestWriteFileMapDirectory(t *testing.T) {
 	assert.Contains(output, "text.txt")
 	assert.Contains(output, textContent)
 }
+
+func TestWriteFileMapCodeFences(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"main.go":   "package main\n",
+		"README.md": "Run:\n```sh\ngo run .\n```",
+		"Makefile":  "all:\n",
+	}
+	for name, content := range files {
+		assert.NoError(os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644))
+	}
+
+	fsys := os.DirFS(tempDir)
+	selections := []selection.FileSelection{
+		selection.NewFileSelection(fsys, "main.go", nil),
+		selection.NewFileSelection(fsys, "README.md", nil),
+		selection.NewFileSelection(fsys, "Makefile", nil),
+		selection.NewFileSelection(fsys, "main.go", []selection.LineRange{{Start: 1, End: 1}}),
+	}
+
+	var buf strings.Builder
+	w := NewWriteFileMapWithOptions(fsys, tempDir, nil, FileMapOptions{CodeFences: true})
+	assert.NoError(w.Output(&buf, selections))
+
+	assert.Equal(
+		"\n<!-- Read File: main.go -->\n```go\npackage main\n```\n"+
+			// the fence grows past the backticks inside the file
+			"\n<!-- Read File: README.md -->\n````markdown\nRun:\n```sh\ngo run .\n```\n````\n"+
+			"\n<!-- Read File: Makefile -->\n```\nall:\n```\n"+
+			"\n<!-- Read File: main.go#1,1 -->\n```go\npackage main\n```\n",
+		buf.String())
+}
+
+func TestWriteFileMapWithOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"main.go":     "package main",
+		"pkg/util.py": "pass",
+	})
+	assert.NoError(err)
+
+	var buf strings.Builder
+	files := []string{"main.go", filepath.Join(tempDir, "pkg", "util.py")}
+	assert.NoError(WriteFileMapWithOptions(&buf, files, tempDir, FileMapOptions{CodeFences: true}))
+	assert.Equal(
+		"\n<!-- Read File: main.go -->\n```go\npackage main\n```\n"+
+			"\n<!-- Read File: pkg/util.py -->\n```python\npass\n```\n",
+		buf.String())
+
+	assert.Error(WriteFileMapWithOptions(&buf, []string{"missing.go"}, tempDir, FileMapOptions{}))
+}
+
+func TestWriteFileMap(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"a.txt":     "alpha\n",
+		"dir/b.txt": "beta\n",
+	})
+	assert.NoError(err)
+	files := []string{"a.txt", "dir/b.txt"}
+	want := "\n<!-- Read File: a.txt -->\nalpha\n\n<!-- Read File: dir/b.txt -->\nbeta\n"
+
+	var buf strings.Builder
+	assert.NoError(WriteFileMap(&buf, files, tempDir))
+	assert.Equal(want, buf.String())
+
+	s, err := WriteFileMapString(files, tempDir)
+	assert.NoError(err)
+	assert.Equal(want, s)
+
+	_, err = WriteFileMapString([]string{"missing.txt"}, tempDir)
+	assert.Error(err)
+}
+
+func TestExtensionToLanguage(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("go", ExtensionToLanguage(".go"))
+	assert.Equal("python", ExtensionToLanguage(".py"))
+	assert.Equal("typescript", ExtensionToLanguage(".TS"))
+	assert.Equal("yaml", ExtensionToLanguage(".yml"))
+	assert.Equal("nim", ExtensionToLanguage(".nim"))
+	assert.Equal("", ExtensionToLanguage(""))
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// vibeFileName is the repo-wide instructions file read by loadVibeFiles
+const vibeFileName = ".vibe.md"
+
+// InitCmd defines the command-line arguments for the init subcommand
+type InitCmd struct {
+	Force bool `arg:"-f,--force" help:"Overwrite an existing .vibe.md"`
+}
+
+// InitRunner writes a starter .vibe.md for the project in RootPath
+type InitRunner struct {
+	Args     InitCmd
+	RootPath string
+	Detector ProjectDetector
+	Output   io.Writer
+}
+
+// NewInitRunner creates and initializes a new InitRunner
+func NewInitRunner(cmd InitCmd, root string) (*InitRunner, error) {
+	return &InitRunner{
+		Args:     cmd,
+		RootPath: root,
+		Output:   os.Stdout,
+	}, nil
+}
+
+// Run executes the init subcommand
+func (r *InitRunner) Run() error {
+	dest := filepath.Join(r.RootPath, vibeFileName)
+	if _, err := os.Stat(dest); err == nil && !r.Args.Force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", dest)
+	}
+
+	info, err := r.Detector.Detect(r.RootPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(dest, []byte(info.VibeFile()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", dest, err)
+	}
+
+	lang := info.Language
+	if lang == "" {
+		lang = "unknown project type"
+	}
+	_, err = fmt.Fprintf(r.Output, "✅ Created %s (%s)\n", dest, lang)
+	return err
+}
+
+// ProjectInfo is what ProjectDetector found out about a project. Fields are
+// empty when nothing was detected.
+type ProjectInfo struct {
+	Language    string
+	Framework   string
+	TestCommand string
+	EntryPoints []string // slash-separated, relative to the root
+	KeyDirs     []string // top-level directories, without vendored or build output
+}
+
+// projectKind describes how to recognise one kind of project.
+type projectKind struct {
+	language    string
+	markers     []string          // any of these files at the root identifies the kind
+	frameworks  map[string]string // dependency name in a marker file -> framework
+	entryGlobs  []string          // fs.Glob patterns for entry points
+	testCommand string
+}
+
+// projectKinds is checked in order; the first kind with a marker wins.
+var projectKinds = []projectKind{
+	{
+		language: "Go",
+		markers:  []string{"go.mod"},
+		frameworks: map[string]string{
+			"github.com/gin-gonic/gin": "Gin",
+			"github.com/labstack/echo": "Echo",
+			"github.com/gofiber/fiber": "Fiber",
+			"github.com/go-chi/chi":    "chi",
+			"github.com/spf13/cobra":   "Cobra",
+		},
+		entryGlobs:  []string{"main.go", "cmd/*/main.go"},
+		testCommand: "go test ./...",
+	},
+	{
+		language: "Rust",
+		markers:  []string{"Cargo.toml"},
+		frameworks: map[string]string{
+			"axum":      "Axum",
+			"actix-web": "Actix Web",
+			"rocket":    "Rocket",
+		},
+		entryGlobs:  []string{"src/main.rs", "src/lib.rs", "src/bin/*.rs"},
+		testCommand: "cargo test",
+	},
+	{
+		language: "Python",
+		markers:  []string{"pyproject.toml", "setup.py", "requirements.txt"},
+		frameworks: map[string]string{
+			"django":  "Django",
+			"flask":   "Flask",
+			"fastapi": "FastAPI",
+		},
+		entryGlobs:  []string{"main.py", "app.py", "manage.py", "src/*/__main__.py", "*/__main__.py"},
+		testCommand: "pytest",
+	},
+	{
+		language: "JavaScript",
+		markers:  []string{"package.json"},
+		frameworks: map[string]string{
+			`"next"`:    "Next.js",
+			`"react"`:   "React",
+			`"vue"`:     "Vue",
+			`"svelte"`:  "Svelte",
+			`"express"`: "Express",
+		},
+		entryGlobs:  []string{"index.js", "index.ts", "src/index.*", "src/main.*"},
+		testCommand: "npm test",
+	},
+}
+
+// skippedDirs are top-level directories that hold dependencies or build
+// output rather than project code.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"venv":         true,
+	"__pycache__":  true,
+}
+
+// ProjectDetector guesses a project's language and layout from marker files
+// such as go.mod or pyproject.toml.
+type ProjectDetector struct{}
+
+// Detect inspects rootPath. An unrecognised project is not an error; the
+// returned ProjectInfo then only lists the key directories.
+func (ProjectDetector) Detect(rootPath string) (ProjectInfo, error) {
+	fsys := os.DirFS(rootPath)
+	var info ProjectInfo
+
+	for _, kind := range projectKinds {
+		marker, ok := firstExisting(fsys, kind.markers)
+		if !ok {
+			continue
+		}
+		info.Language = kind.language
+		info.TestCommand = kind.testCommand
+		if kind.language == "JavaScript" && fileExistsFS(fsys, "tsconfig.json") {
+			info.Language = "TypeScript"
+		}
+
+		blob, err := fs.ReadFile(fsys, marker)
+		if err != nil {
+			return ProjectInfo{}, fmt.Errorf("failed to read %s: %v", marker, err)
+		}
+		info.Framework = detectFramework(strings.ToLower(string(blob)), kind.frameworks)
+
+		for _, pattern := range kind.entryGlobs {
+			matches, err := fs.Glob(fsys, pattern)
+			if err != nil {
+				return ProjectInfo{}, err
+			}
+			info.EntryPoints = append(info.EntryPoints, matches...)
+		}
+		break
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return ProjectInfo{}, fmt.Errorf("failed to read %s: %v", rootPath, err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() && !strings.HasPrefix(name, ".") && !skippedDirs[name] {
+			info.KeyDirs = append(info.KeyDirs, name)
+		}
+	}
+
+	return info, nil
+}
+
+// detectFramework returns the framework whose dependency name appears in
+// the marker file. Names are checked in sorted order so the result does not
+// depend on map iteration.
+func detectFramework(marker string, frameworks map[string]string) string {
+	deps := make([]string, 0, len(frameworks))
+	for dep := range frameworks {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	for _, dep := range deps {
+		if strings.Contains(marker, strings.ToLower(dep)) {
+			return frameworks[dep]
+		}
+	}
+	return ""
+}
+
+func firstExisting(fsys fs.FS, names []string) (string, bool) {
+	for _, name := range names {
+		if fileExistsFS(fsys, name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func fileExistsFS(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// VibeFile renders the starter .vibe.md for the project.
+func (p ProjectInfo) VibeFile() string {
+	var b strings.Builder
+	b.WriteString("# Project Overview\n\n")
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", name, value)
+		}
+	}
+	codeList := func(items []string, suffix string) string {
+		quoted := make([]string, len(items))
+		for i, s := range items {
+			quoted[i] = "`" + s + suffix + "`"
+		}
+		return strings.Join(quoted, ", ")
+	}
+
+	field("Language", p.Language)
+	field("Framework", p.Framework)
+	field("Entry points", codeList(p.EntryPoints, ""))
+	field("Key directories", codeList(p.KeyDirs, "/"))
+	if p.Language == "" && len(p.KeyDirs) == 0 {
+		b.WriteString("<!-- What does this project do, and where does its code live? -->\n")
+	}
+
+	b.WriteString("\n# Conventions\n\n")
+	if p.TestCommand != "" {
+		fmt.Fprintf(&b, "- Run `%s` after making changes\n", p.TestCommand)
+	}
+	b.WriteString("<!-- Coding style, error handling, test layout: anything an assistant should follow. -->\n")
+	return b.String()
+}
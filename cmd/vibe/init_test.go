@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestProjectDetector(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  ProjectInfo
+	}{
+		{
+			name: "go",
+			files: map[string]string{
+				"go.mod":              "module example.com/x\n\nrequire github.com/go-chi/chi/v5 v5.0.0\n",
+				"cmd/server/main.go":  "package main",
+				"internal/db/db.go":   "package db",
+				"vendor/x/x.go":       "package x",
+				".github/ci.yml":      "",
+				"cmd/server/serve.go": "package main",
+			},
+			want: ProjectInfo{
+				Language:    "Go",
+				Framework:   "chi",
+				TestCommand: "go test ./...",
+				EntryPoints: []string{"cmd/server/main.go"},
+				KeyDirs:     []string{"cmd", "internal"},
+			},
+		},
+		{
+			name: "python",
+			files: map[string]string{
+				"pyproject.toml":   "[project]\ndependencies = [\"FastAPI>=0.100\"]\n",
+				"app.py":           "",
+				"tests/test_x.py":  "",
+				"__pycache__/x.pc": "",
+			},
+			want: ProjectInfo{
+				Language:    "Python",
+				Framework:   "FastAPI",
+				TestCommand: "pytest",
+				EntryPoints: []string{"app.py"},
+				KeyDirs:     []string{"tests"},
+			},
+		},
+		{
+			name: "typescript",
+			files: map[string]string{
+				"package.json":   `{"dependencies": {"next": "14", "react": "18"}}`,
+				"tsconfig.json":  "{}",
+				"src/index.ts":   "",
+				"node_modules/a": "",
+			},
+			want: ProjectInfo{
+				Language:    "TypeScript",
+				Framework:   "Next.js",
+				TestCommand: "npm test",
+				EntryPoints: []string{"src/index.ts"},
+				KeyDirs:     []string{"src"},
+			},
+		},
+		{
+			name:  "unknown",
+			files: map[string]string{"docs/readme.txt": ""},
+			want:  ProjectInfo{KeyDirs: []string{"docs"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			dir, err := createTestDirectory(t, tc.files)
+			assert.NoError(err)
+			info, err := ProjectDetector{}.Detect(dir)
+			assert.NoError(err)
+			assert.Equal(tc.want, info)
+		})
+	}
+}
+
+func TestInitRunner(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := createTestDirectory(t, map[string]string{
+		"go.mod":   "module example.com/x\n",
+		"main.go":  "package main",
+		"pkg/a.go": "package pkg",
+	})
+	assert.NoError(err)
+
+	runner, err := NewInitRunner(InitCmd{}, dir)
+	assert.NoError(err)
+	var out bytes.Buffer
+	runner.Output = &out
+	assert.NoError(runner.Run())
+	assert.Contains(out.String(), "(Go)")
+
+	got, err := os.ReadFile(filepath.Join(dir, ".vibe.md"))
+	assert.NoError(err)
+	assert.Equal("# Project Overview\n\n"+
+		"- Language: Go\n"+
+		"- Entry points: `main.go`\n"+
+		"- Key directories: `pkg/`\n"+
+		"\n# Conventions\n\n"+
+		"- Run `go test ./...` after making changes\n"+
+		"<!-- Coding style, error handling, test layout: anything an assistant should follow. -->\n",
+		string(got))
+
+	// an existing .vibe.md is kept unless --force is given
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".vibe.md"), []byte("mine"), 0644))
+	assert.ErrorContains(runner.Run(), "already exists")
+	got, _ = os.ReadFile(filepath.Join(dir, ".vibe.md"))
+	assert.Equal("mine", string(got))
+
+	runner.Args.Force = true
+	assert.NoError(runner.Run())
+	got, _ = os.ReadFile(filepath.Join(dir, ".vibe.md"))
+	assert.Contains(string(got), "- Language: Go")
+}
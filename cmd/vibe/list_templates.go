@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ListTemplatesCmd defines the command-line arguments for the list-templates subcommand
+type ListTemplatesCmd struct {
+	Tag string `arg:"--tag" help:"Only list templates whose front matter tags include this tag"`
+}
+
+// ListTemplatesRunner prints the templates found in the lookup paths
+type ListTemplatesRunner struct {
+	Args     ListTemplatesCmd
+	RootPath string
+	Output   io.Writer
+}
+
+// NewListTemplatesRunner creates and initializes a new ListTemplatesRunner
+func NewListTemplatesRunner(cmd ListTemplatesCmd, root string) (*ListTemplatesRunner, error) {
+	return &ListTemplatesRunner{
+		Args:     cmd,
+		RootPath: root,
+		Output:   os.Stdout,
+	}, nil
+}
+
+// Run executes the list-templates subcommand
+func (r *ListTemplatesRunner) Run() error {
+	resolver, err := templateResolver(r.RootPath)
+	if err != nil {
+		return err
+	}
+
+	// templates with broken front matter are reported after the others
+	paths, listErr := resolver.ListTemplates(r.Args.Tag)
+	for _, p := range paths {
+		if _, err := fmt.Fprintln(r.Output, p); err != nil {
+			return err
+		}
+	}
+	return listErr
+}
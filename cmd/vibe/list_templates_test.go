@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestListTemplatesRunner(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VIBE_PROMPTS", "")
+
+	tempDir, err := createTestDirectory(t, map[string]string{
+		".gitignore":                 "node_modules/\n",
+		".vibeIgnore":                "vendor/\n",
+		"review.md":                  "---toml\ntags = [\"review\"]\n---\n",
+		"docs/explain.md":            "---toml\ntags = [\"docs\"]\n---\n",
+		"node_modules/pkg/README.md": "---toml\ntags = [\"review\"]\n---\n",
+		"vendor/lib/README.md":       "---toml\ntags = [\"review\"]\n---\n",
+	})
+	assert.NoError(err)
+
+	run := func(cmd ListTemplatesCmd) (string, error) {
+		runner, err := NewListTemplatesRunner(cmd, tempDir)
+		assert.NoError(err)
+		var out bytes.Buffer
+		runner.Output = &out
+		err = runner.Run()
+		return out.String(), err
+	}
+
+	out, err := run(ListTemplatesCmd{Tag: "review"})
+	assert.NoError(err)
+	assert.Equal("review.md\n", out)
+	out, err = run(ListTemplatesCmd{Tag: "missing"})
+	assert.NoError(err)
+	assert.Equal("", out)
+
+	// without a tag, builtin .md templates are listed too
+	all, err := run(ListTemplatesCmd{})
+	assert.NoError(err)
+	assert.Contains(all, "docs/explain.md\n")
+	assert.Contains(all, "files.cc.md\n")
+	assert.NotContains(all, "README.md")
+
+	// broken front matter fails the command, after listing the rest
+	assert.NoError(os.WriteFile(filepath.Join(tempDir, "broken.md"), []byte("---toml\ntags = [\n---\n"), 0644))
+	out, err = run(ListTemplatesCmd{Tag: "review"})
+	assert.ErrorContains(err, "broken.md")
+	assert.Equal("review.md\n", out)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"sort"
 
@@ -12,15 +14,20 @@ import (
 type LsCmd struct {
 	Select string `arg:"-s,--select" help:"Select files matching patterns"`
 	Mode   string `arg:"--mode,-m" help:"Template specialization mode"`
+	Tokens bool   `arg:"--tokens" help:"Show the estimated token count of each file"`
+	SortBy string `arg:"--sort-by" help:"Sort by 'path' (default) or 'tokens' (descending; implies --tokens)"`
+	// TokenEstimator mirrors the out flag: 'simple' (default) or 'tiktoken'
+	TokenEstimator string `arg:"--token-estimator" help:"Token count estimator to use: 'simple' (size/4, default) or 'tiktoken'"`
 	// optional positional prompt file (template); empty means rely on --select
 	Template string `arg:"positional" help:"Path to a prompt/template file"`
 }
 
 // LsRunner encapsulates the state and behavior for the ls subcommand
 type LsRunner struct {
-	Args     LsCmd
-	RootPath string
-	DirTree  *DirectoryTree
+	Args           LsCmd
+	RootPath       string
+	DirTree        *DirectoryTree
+	TokenEstimator TokenEstimator
 }
 
 // NewLsRunner creates and initializes a new LsRunner
@@ -28,26 +35,38 @@ func NewLsRunner(cmd LsCmd, root string) (*LsRunner, error) {
 	if cmd.Select == "" && cmd.Template == "" {
 		return nil, fmt.Errorf("either --select or <template> must be provided")
 	}
+	switch cmd.SortBy {
+	case "", "path":
+	case "tokens":
+		cmd.Tokens = true
+	default:
+		return nil, fmt.Errorf("unknown --sort-by %q, use 'path' or 'tokens'", cmd.SortBy)
+	}
+	tokenEstimator, err := tokenEstimatorFor(cmd.TokenEstimator)
+	if err != nil {
+		return nil, err
+	}
 	return &LsRunner{
-		Args:     cmd,
-		RootPath: root,
-		DirTree:  NewDirectoryTree(root),
+		Args:           cmd,
+		RootPath:       root,
+		DirTree:        NewDirectoryTree(root),
+		TokenEstimator: tokenEstimator,
 	}, nil
 }
 
 // Run executes the ls subcommand
 func (r *LsRunner) Run() error {
-	pattern := r.Args.Select
+	pattern, exclude := r.Args.Select, ""
 	if pattern == "" { // derive from template front-matter
 		resolver := render.NewResolver(r.Args.Mode, os.DirFS(r.RootPath))
 		templ, err := render.NewRenderer(resolver, nil).LoadTemplate(r.Args.Template)
 		if err != nil {
 			return err
 		}
-		pattern = templ.FrontMatter.Select
+		pattern, exclude = templ.FrontMatter.Select, templ.FrontMatter.Exclude
 	}
 
-	selections, err := r.DirTree.SelectFiles(pattern)
+	selections, err := r.DirTree.SelectFiles(pattern, exclude)
 	if err != nil {
 		return err
 	}
@@ -58,8 +77,45 @@ func (r *LsRunner) Run() error {
 	}
 	sort.Strings(paths)
 
+	if r.Args.Tokens {
+		return writeTokenListing(os.Stdout, r.DirTree.fsys, paths, r.TokenEstimator, r.Args.SortBy)
+	}
+
 	for _, p := range paths {
 		fmt.Println(p)
 	}
 	return nil
 }
+
+// writeTokenListing prints "path<TAB>tokens" for every path, followed by a
+// TOTAL line. Each line has exactly one tab, so it splits cleanly with cut or
+// awk -F'\t'. With sortBy "tokens" the largest files come
+// first; otherwise paths keep their given order.
+func writeTokenListing(w io.Writer, fsys fs.FS, paths []string, estimate TokenEstimator, sortBy string) error {
+	type row struct {
+		path   string
+		tokens int
+	}
+	rows := make([]row, 0, len(paths))
+	total := 0
+	for _, p := range paths {
+		n, err := estimate(fsys, p)
+		if err != nil {
+			return fmt.Errorf("failed to estimate tokens for %s: %w", p, err)
+		}
+		rows = append(rows, row{p, n})
+		total += n
+	}
+
+	if sortBy == "tokens" {
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].tokens > rows[j].tokens })
+	}
+
+	for _, r := range rows {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", r.path, r.tokens); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "TOTAL\t%d\n", total)
+	return err
+}
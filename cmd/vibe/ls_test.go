@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/hayeah/fork2/internal/assert"
 )
@@ -117,3 +119,50 @@ func TestLsRunner(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteTokenListing(t *testing.T) {
+	assert := assert.New(t)
+
+	fsys := fstest.MapFS{
+		"a.go":            {Data: []byte(strings.Repeat("x", 40))},  // 10 tokens
+		"internal/big.go": {Data: []byte(strings.Repeat("x", 400))}, // 100 tokens
+		"c.md":            {Data: []byte(strings.Repeat("x", 8))},   // 2 tokens
+	}
+	paths := []string{"a.go", "c.md", "internal/big.go"}
+
+	var buf bytes.Buffer
+	assert.NoError(writeTokenListing(&buf, fsys, paths, estimateTokenCountSimple, ""))
+	// exactly one tab separates the path from its count
+	assert.Equal(
+		"a.go\t10\n"+
+			"c.md\t2\n"+
+			"internal/big.go\t100\n"+
+			"TOTAL\t112\n",
+		buf.String())
+
+	buf.Reset()
+	assert.NoError(writeTokenListing(&buf, fsys, paths, estimateTokenCountSimple, "tokens"))
+	var order []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Split(line, "\t")
+		assert.Equal(2, len(fields))
+		order = append(order, fields[0])
+	}
+	assert.Equal([]string{"internal/big.go", "a.go", "c.md", "TOTAL"}, order)
+
+	assert.Error(writeTokenListing(&buf, fsys, []string{"missing.go"}, estimateTokenCountSimple, ""))
+}
+
+func TestNewLsRunner_SortBy(t *testing.T) {
+	assert := assert.New(t)
+
+	runner, err := NewLsRunner(LsCmd{Select: ".go", SortBy: "tokens"}, t.TempDir())
+	assert.NoError(err)
+	assert.True(runner.Args.Tokens)
+
+	_, err = NewLsRunner(LsCmd{Select: ".go", SortBy: "size"}, t.TempDir())
+	assert.Error(err)
+
+	_, err = NewLsRunner(LsCmd{Select: ".go", TokenEstimator: "bogus"}, t.TempDir())
+	assert.Error(err)
+}
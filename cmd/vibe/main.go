@@ -12,8 +12,14 @@ import (
 // Args defines the command-line arguments with subcommands
 type Args struct {
 	Out                *OutCmd                `arg:"subcommand:out" help:"Select files and generate output"`
+	Diff               *DiffCmd               `arg:"subcommand:diff" help:"Generate a review prompt for the changes against a git ref"`
 	Ls                 *LsCmd                 `arg:"subcommand:ls" help:"List files matching patterns"`
 	New                *NewCmd                `arg:"subcommand:new" help:"Create a new prompt/template"`
+	Init               *InitCmd               `arg:"subcommand:init" help:"Write a starter .vibe.md for the current project"`
+	Check              *CheckCmd              `arg:"subcommand:check" help:"Lint all templates without rendering"`
+	ListTemplates      *ListTemplatesCmd      `arg:"subcommand:list-templates" help:"List templates, optionally filtered by tag"`
+	Completion         *CompletionCmd         `arg:"subcommand:completion" help:"Print a shell completion script"`
+	Profile            *ProfileCmd            `arg:"subcommand:profile" help:"Manage saved select profiles"`
 	InstallVSCodeTasks *InstallVSCodeTasksCmd `arg:"subcommand:install:vscode:tasks" help:"Install VS Code tasks for vibe"`
 }
 
@@ -46,11 +52,35 @@ func NewRunner(args Args) *Runner {
 func (r *Runner) Run() error {
 	switch {
 	case r.Args.Out != nil:
-		pickRunner, err := NewAskRunner(*r.Args.Out)
+		root := r.RootPath
+		if r.Args.Out.Root != "" {
+			root = r.Args.Out.Root
+		}
+		cfg, err := LoadConfig(root)
+		if err != nil {
+			return err
+		}
+		outArgs, err := cfg.ApplyProfile(*r.Args.Out)
+		if err != nil {
+			return err
+		}
+		pickRunner, err := NewAskRunner(outArgs)
 		if err != nil {
 			return err
 		}
-		return pickRunner.Run()
+		if err := pickRunner.Run(); err != nil {
+			return err
+		}
+		if err := recordSelectHistory(r.Args.Out.Select); err != nil {
+			log.Printf("failed to record select history: %v", err)
+		}
+		return nil
+	case r.Args.Diff != nil:
+		diffRunner, err := NewDiffRunner(*r.Args.Diff, r.RootPath)
+		if err != nil {
+			return err
+		}
+		return diffRunner.Run()
 	case r.Args.Ls != nil:
 		lsRunner, err := NewLsRunner(*r.Args.Ls, r.RootPath)
 		if err != nil {
@@ -63,11 +93,41 @@ func (r *Runner) Run() error {
 			return err
 		}
 		return newRunner.Run()
+	case r.Args.Init != nil:
+		initRunner, err := NewInitRunner(*r.Args.Init, r.RootPath)
+		if err != nil {
+			return err
+		}
+		return initRunner.Run()
+	case r.Args.Check != nil:
+		checkRunner, err := NewCheckRunner(*r.Args.Check, r.RootPath)
+		if err != nil {
+			return err
+		}
+		return checkRunner.Run()
+	case r.Args.ListTemplates != nil:
+		listRunner, err := NewListTemplatesRunner(*r.Args.ListTemplates, r.RootPath)
+		if err != nil {
+			return err
+		}
+		return listRunner.Run()
+	case r.Args.Completion != nil:
+		completionRunner, err := NewCompletionRunner(*r.Args.Completion, r.RootPath)
+		if err != nil {
+			return err
+		}
+		return completionRunner.Run()
+	case r.Args.Profile != nil:
+		profileRunner, err := NewProfileRunner(*r.Args.Profile)
+		if err != nil {
+			return err
+		}
+		return profileRunner.Run()
 	case r.Args.InstallVSCodeTasks != nil:
 		vsctRunner := NewInstallVSCodeTasksRunner(r.RootPath)
 		return vsctRunner.Run()
 	default:
-		return fmt.Errorf("no subcommand specified, use 'out', 'ls', 'new', or 'install:vscode:tasks'")
+		return fmt.Errorf("no subcommand specified, use 'out', 'diff', 'ls', 'new', 'init', 'check', 'list-templates', 'completion', 'profile', or 'install:vscode:tasks'")
 	}
 }
 
@@ -77,7 +137,7 @@ func main() {
 	parser := arg.MustParse(&args)
 
 	// If no subcommand is specified, show help
-	if args.Out == nil && args.Ls == nil && args.New == nil && args.InstallVSCodeTasks == nil {
+	if args.Out == nil && args.Diff == nil && args.Ls == nil && args.New == nil && args.Init == nil && args.Check == nil && args.ListTemplates == nil && args.Completion == nil && args.Profile == nil && args.InstallVSCodeTasks == nil {
 		parser.WriteHelp(os.Stderr)
 		os.Exit(1)
 	}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/hayeah/fork2/internal/metrics"
 	"github.com/hayeah/fork2/internal/metrics/chart"
@@ -18,6 +21,39 @@ func termWidth() int {
 
 // PrintTokenBreakdown keeps its old signature so no other call-sites change.
 func PrintTokenBreakdown(m *metrics.OutputMetrics) error {
+	return PrintTokenBreakdownFormat(m, "text")
+}
+
+// PrintTokenBreakdownFormat prints the token chart to stdout as "text",
+// "svg" or "html". The cost estimate is only appended to the text chart.
+func PrintTokenBreakdownFormat(m *metrics.OutputMetrics, format string) error {
 	opt := chart.DefaultOptions(termWidth, os.Stdout)
-	return chart.Print(m, opt)
+	switch format {
+	case "", "text":
+		if err := chart.Print(m, opt); err != nil {
+			return err
+		}
+		return printCostEstimate(os.Stdout, m, metrics.DefaultPriceTables())
+	case "svg":
+		return chart.PrintSVG(m, opt, os.Stdout)
+	case "html":
+		return chart.PrintHTML(m, opt, os.Stdout)
+	default:
+		return fmt.Errorf("unknown metrics format %q (want text, svg or html)", format)
+	}
+}
+
+// printCostEstimate writes one line with the estimated input cost of the
+// output for every model in table.
+func printCostEstimate(w io.Writer, m *metrics.OutputMetrics, table metrics.PriceTable) error {
+	var parts []string
+	for _, model := range table.Models() {
+		est, err := metrics.EstimateCost(m, model, table)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, fmt.Sprintf("%s $%.4f", model, est.EstimatedUSD))
+	}
+	_, err := fmt.Fprintf(w, "Estimated input cost: %s\n", strings.Join(parts, ", "))
+	return err
 }
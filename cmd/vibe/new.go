@@ -2,7 +2,9 @@ package main
 
 import (
 	_ "embed" // Used for go:embed directive
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -20,6 +22,8 @@ var defaultTemplate string
 type NewCmd struct {
 	Copy   string `arg:"--copy" help:"Seed the new file from an existing template"`
 	Dir    string `arg:"-d,--dir" help:"Directory to save the new file"`
+	List   bool   `arg:"--list" help:"List available templates with their descriptions"`
+	JSON   bool   `arg:"--json" help:"With --list, print the templates as JSON"`
 	Target string `arg:"positional" help:"Either the task name in free-text, or a path ending in .md"`
 }
 
@@ -27,22 +31,37 @@ type NewCmd struct {
 type NewCmdRunner struct {
 	Args     NewCmd
 	RootPath string
+	Output   io.Writer
+}
+
+// templateListing is one entry printed by `new --list`
+type templateListing struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
 }
 
 // NewNewRunner creates and initializes a new NewCmdRunner
 func NewNewRunner(cmdArgs NewCmd, rootPath string) (*NewCmdRunner, error) {
-	if cmdArgs.Target == "" && cmdArgs.Copy == "" {
-		return nil, fmt.Errorf("either a task name or --copy must be provided")
+	if cmdArgs.JSON && !cmdArgs.List {
+		return nil, fmt.Errorf("--json can only be used with --list")
+	}
+	if cmdArgs.Target == "" && cmdArgs.Copy == "" && !cmdArgs.List {
+		return nil, fmt.Errorf("either a task name, --copy or --list must be provided")
 	}
 
 	return &NewCmdRunner{
 		Args:     cmdArgs,
 		RootPath: rootPath,
+		Output:   os.Stdout,
 	}, nil
 }
 
 // Run executes the new command process
 func (r *NewCmdRunner) Run() error {
+	if r.Args.List {
+		return r.runList()
+	}
+
 	// Determine the source template content
 	var templateContent string
 	var err error
@@ -139,6 +158,41 @@ func (r *NewCmdRunner) Run() error {
 	return nil
 }
 
+// runList prints every template in the lookup paths along with the
+// description from its front matter. Only front matter is parsed.
+func (r *NewCmdRunner) runList() error {
+	resolver, err := templateResolver(r.RootPath)
+	if err != nil {
+		return err
+	}
+
+	paths, err := resolver.ListTemplates("")
+	if err != nil {
+		return err
+	}
+
+	listings := make([]templateListing, 0, len(paths))
+	for _, p := range paths {
+		// a template with broken front matter is still listed; `vibe check`
+		// is the place to report the error
+		meta, _ := resolver.LoadFrontMatter(p)
+		listings = append(listings, templateListing{Path: p, Description: meta.Description})
+	}
+
+	if r.Args.JSON {
+		enc := json.NewEncoder(r.Output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(listings)
+	}
+
+	for _, l := range listings {
+		if _, err := fmt.Fprintf(r.Output, "%s\t%s\n", l.Path, l.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // currentCommit returns the current git commit hash or "unknown" on failure
 func currentCommit() string {
 	cmd := exec.Command("git", "rev-parse", "HEAD")
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestNewCmdRunner_List(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VIBE_PROMPTS", "")
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"review.md":       "---toml\ndescription = \"Review a change\"\n---\n{{ if }}",
+		"plain.md":        "no front matter",
+		"files.cc.md":     "---\ndescription: Repo override of the builtin\n---\n",
+		".hidden/skip.md": "---toml\ndescription = \"hidden\"\n---\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		assert.NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+
+	runner, err := NewNewRunner(NewCmd{List: true}, tempDir)
+	assert.NoError(err)
+	var out bytes.Buffer
+	runner.Output = &out
+	assert.NoError(runner.Run())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(lines, "review.md\tReview a change")
+	assert.Contains(lines, "plain.md\t")
+	// the repo copy shadows the builtin files.cc.md
+	assert.Contains(lines, "files.cc.md\tRepo override of the builtin")
+	assert.Equal(1, strings.Count(out.String(), "files.cc.md"))
+	assert.NotContains(out.String(), "hidden")
+
+	runner, err = NewNewRunner(NewCmd{List: true, JSON: true}, tempDir)
+	assert.NoError(err)
+	out.Reset()
+	runner.Output = &out
+	assert.NoError(runner.Run())
+
+	var listings []templateListing
+	assert.NoError(json.Unmarshal(out.Bytes(), &listings))
+	assert.Contains(listings, templateListing{Path: "review.md", Description: "Review a change"})
+
+	_, err = NewNewRunner(NewCmd{JSON: true}, tempDir)
+	assert.Error(err)
+}
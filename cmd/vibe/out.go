@@ -1,38 +1,72 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
+	"maps"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/atotto/clipboard"
 	"github.com/hayeah/fork2/internal/metrics"
+	"github.com/hayeah/fork2/render"
 	"github.com/pkoukk/tiktoken-go"
+	"golang.org/x/term"
 )
 
+// readClipboard reads the system clipboard for {{ .ClipboardText }}; tests
+// replace it.
+var readClipboard = clipboard.ReadAll
+
 // OutCmd contains the arguments for the 'out' subcommand
 type OutCmd struct {
-	TokenEstimator string `arg:"--token-estimator" help:"Token count estimator to use: 'simple' (size/4) or 'tiktoken'" default:"simple"`
+	TokenEstimator string `arg:"--token-estimator" help:"Token count estimator to use: 'simple' (size/4, default) or 'tiktoken'"`
 	All            bool   `arg:"-a,--all" help:"Select all files and output immediately"`
 	// Output sets the destination for the generated prompt: '-' for stdout, a file path to write the output, or empty to copy to clipboard
-	Output        string   `arg:"-o,--output" help:"Output destination: '-' for stdout; file path to write; if not set, copy to clipboard"`
-	Layout        string   `arg:"--layout" help:"Layout to use for output"`
-	Select        string   `arg:"-s,--select" help:"Select files matching patterns"`
-	SelectDirTree string   `arg:"-t,--dirtree" help:"Filter the directory-tree diagram with the same pattern syntax as --select"`
-	Data          []string `arg:"-d,--data,separate" help:"key=value pairs exposed to templates as .Data.* (repeatable)"`
-	Metrics       string   `arg:"-m,--metrics" help:"Write metrics JSON ('-' = stdout)"`
-	Content       []string `arg:"-c,--content,separate" help:"Content source specifications: '-' for stdin, file paths, URLs, or literals (repeatable)"`
-	Mode          string   `arg:"--mode,-m" help:"Template specialization mode"`
-	Root          string   `arg:"-r,--root" help:"Path to repo root (default: .)"`
-	Template      string   `arg:"positional" help:"User instruction or path to instruction file"`
-	TemplatePaths []string // Additional paths to search for templates (not exposed as CLI arg)
+	Output         string         `arg:"-o,--output" help:"Output destination: '-' for stdout; file path to write; if not set, copy to clipboard"`
+	OutputFormat   string         `arg:"--output-format" help:"Output format: text (default) or jsonl, one JSON object per rendered section"`
+	OutputTreeJSON string         `arg:"--output-tree-json" help:"Also write the directory tree as JSON to this path"`
+	Layout         []string       `arg:"--layout,separate" help:"Layout to use for output; repeat or separate with ';' to chain (outermost first)"`
+	Select         string         `arg:"-s,--select" help:"Select files matching patterns"`
+	Exclude        string         `arg:"-x,--exclude" help:"Drop selected files matching patterns (same syntax as --select)"`
+	SelectDirTree  string         `arg:"-t,--dirtree" help:"Filter the directory-tree diagram with the same pattern syntax as --select"`
+	Data           []string       `arg:"-d,--data,separate" help:"key=value pairs exposed to templates as .Data.* (repeatable)"`
+	DataFile       string         `arg:"--data-file" help:"TOML or JSON file of key/value pairs for .Data.*; --data overrides it"`
+	Metrics        string         `arg:"-m,--metrics" help:"Write metrics JSON ('-' = stdout); a .csv path writes CSV"`
+	MetricsFormat  string         `arg:"--metrics-format" help:"Token chart format: text (default), svg or html"`
+	CodeFences     bool           `arg:"--code-fences" help:"Wrap each selected file in a Markdown code fence"`
+	Content        []string       `arg:"-c,--content,separate" help:"Content source specifications: '-' for stdin, file paths, URLs, or literals (repeatable)"`
+	Mode           string         `arg:"--mode,-m" help:"Template specialization mode"`
+	Profile        string         `arg:"--profile" help:"Named preset from [profiles.NAME] in vibe.toml or ~/.vibe/profiles.toml"`
+	Root           string         `arg:"-r,--root" help:"Path to repo root (default: .)"`
+	MaxDepth       int            `arg:"--max-depth" help:"Maximum directory depth to walk (0 = unlimited)"`
+	NewerThan      *time.Duration `arg:"--newer-than" help:"Only include files modified within this duration (e.g. 48h)"`
+	MaxTokens      int            `arg:"--max-tokens" help:"Abort if the output exceeds this many tokens (0 = unlimited)"`
+	MaxFiles       int            `arg:"--max-files" help:"Keep only the first N selected files, in path order (0 = unlimited)"`
+	Watch          bool           `arg:"--watch" help:"Re-render whenever a template or repo file changes"`
+	Edit           bool           `arg:"--edit" help:"Open the output in $EDITOR before copying or writing it"`
+	Trace          string         `arg:"--trace" help:"Write each template's output, wrapped in BEGIN/END comments, to this file"`
+	Template       string         `arg:"positional" help:"User instruction or path to instruction file"`
+	Stdin          bool           `arg:"--stdin" help:"Read the template body from stdin instead of a file"`
+	TemplatePaths  []string       // Additional paths to search for templates (not exposed as CLI arg)
+	GitDiff        string         `arg:"-"` // diff text exposed to templates as .GitDiff, set by 'vibe diff'
+	// ContentTransform is a shell command each loaded content source is piped through
+	ContentTransform string `arg:"--content-transform" help:"Shell command to pipe each content source through, e.g. 'jq .results'"`
+	// Defaults are front matter values from vibe.toml, see Config.Apply
+	Defaults TemplateDefaults `arg:"-"`
 }
 
 // OutRunner encapsulates the state and behavior for the file picker
@@ -43,6 +77,10 @@ type OutRunner struct {
 	TokenEstimator TokenEstimator
 	Data           map[string]string
 	Metrics        *metrics.OutputMetrics
+
+	// Prompt is read for required Data values that were not given; nil
+	// (the default when stdin is not a terminal) makes them an error instead
+	Prompt io.Reader
 }
 
 // NewAskRunner creates and initializes a new PickRunner
@@ -62,19 +100,33 @@ func NewAskRunner(cmdArgs OutCmd) (*OutRunner, error) {
 		return nil, fmt.Errorf("not a directory: %s", root)
 	}
 
+	if cmdArgs.Edit && cmdArgs.Watch {
+		return nil, fmt.Errorf("--edit cannot be combined with --watch")
+	}
+
+	if cmdArgs.OutputFormat != "" && cmdArgs.OutputFormat != "text" && cmdArgs.OutputFormat != "jsonl" {
+		return nil, fmt.Errorf("unknown output format %q, use 'text' or 'jsonl'", cmdArgs.OutputFormat)
+	}
+
+	if cmdArgs.Stdin && cmdArgs.Template != "" {
+		return nil, fmt.Errorf("--stdin cannot be combined with a template argument")
+	}
+	if cmdArgs.Stdin && slices.Contains(cmdArgs.Content, "-") {
+		return nil, fmt.Errorf("--stdin cannot be combined with --content -")
+	}
+	// stdin is used up by the first render, so re-renders would be empty
+	if cmdArgs.Stdin && cmdArgs.Watch {
+		return nil, fmt.Errorf("--stdin cannot be combined with --watch")
+	}
+
 	// Select the token estimator based on the flag
-	var tokenEstimator TokenEstimator
-	switch cmdArgs.TokenEstimator {
-	case "tiktoken":
-		tokenEstimator = estimateTokenCountTiktoken
-	case "simple":
-		tokenEstimator = estimateTokenCountSimple
-	default:
-		return nil, fmt.Errorf("unknown token estimator: %s", cmdArgs.TokenEstimator)
+	tokenEstimator, err := tokenEstimatorFor(cmdArgs.TokenEstimator)
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse data parameters (key=value pairs)
-	data, err := parseDataParams(cmdArgs.Data)
+	// Parse data parameters (data file, then key=value pairs)
+	data, err := loadData(cmdArgs.DataFile, cmdArgs.Data)
 	if err != nil {
 		return nil, err
 	}
@@ -100,48 +152,298 @@ func NewAskRunner(cmdArgs OutCmd) (*OutRunner, error) {
 		Metrics:        metrics.NewOutputMetrics(counter, runtime.NumCPU()),
 	}
 
+	// stdin is only free for prompting if it is not carrying the template or content
+	if term.IsTerminal(int(os.Stdin.Fd())) && !cmdArgs.Stdin && !slices.Contains(cmdArgs.Content, "-") {
+		r.Prompt = os.Stdin
+	}
+
 	return r, nil
 }
 
 // Run executes the file picking process
 func (r *OutRunner) Run() error {
+	out, pipe, err := r.render()
+	if err != nil {
+		return err
+	}
+
+	if r.Args.Edit {
+		if out, err = editOutput(out); err != nil {
+			return err
+		}
+		if err := PrintTokenBreakdownFormat(pipe.Metrics, pipe.MetricsFormat); err != nil {
+			return err
+		}
+	}
+
+	if err := r.emit(out); err != nil {
+		return err
+	}
+
+	if err := r.writeMetrics(pipe.Metrics); err != nil {
+		return err
+	}
+
+	if err := r.writeTreeJSON(pipe.DT); err != nil {
+		return err
+	}
+
+	if r.Args.Watch {
+		return r.watch(out)
+	}
+	return nil
+}
+
+// promptRequired asks on r.Prompt for each Data value that the template
+// requires but --data and --data-file did not provide. Answers are added to
+// r.Args.Data, so --watch re-renders do not ask again.
+func (r *OutRunner) promptRequired(pipe *OutPipeline) error {
+	if r.Prompt == nil || pipe.Template == nil {
+		return nil
+	}
+
+	var in *bufio.Reader
+	for _, path := range pipe.Template.FrontMatter.Require {
+		path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+		key, ok := strings.CutPrefix(path, "Data.")
+		if !ok || strings.Contains(key, ".") || r.Data[key] != "" {
+			continue
+		}
+
+		if in == nil {
+			in = bufio.NewReader(r.Prompt)
+		}
+		fmt.Fprintf(os.Stderr, "Enter value for %s: ", path)
+		line, err := in.ReadString('\n')
+		value := strings.TrimRight(line, "\r\n")
+		if err != nil && (err != io.EOF || value == "") {
+			return fmt.Errorf("failed to read value for %s: %v", path, err)
+		}
+
+		r.Data[key] = value
+		// --data pairs are parsed as a query string
+		r.Args.Data = append(r.Args.Data, key+"="+url.QueryEscape(value))
+	}
+	pipe.Env.DataPairs = r.Args.Data
+	return nil
+}
+
+// render builds a fresh pipeline and renders it into memory.
+func (r *OutRunner) render() ([]byte, *OutPipeline, error) {
 	// Gather files/dirs
-	r.DirTree = NewDirectoryTree(r.RootPath)
+	r.DirTree = NewDirectoryTreeWithOptions(r.RootPath, directoryTreeOptions(r.Args))
+
+	pipe, err := BuildOutPipeline(r.RootPath, r.Args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.promptRequired(pipe); err != nil {
+		return nil, nil, err
+	}
+
+	if r.Args.Trace != "" {
+		trace, err := os.Create(r.Args.Trace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create trace file: %w", err)
+		}
+		defer trace.Close()
+		pipe.Renderer.WithTracing(trace)
+	}
+
+	pipe.ContentSpecs = r.Args.Content
+	pipe.MetricsFormat = r.Args.MetricsFormat
+	// with --edit the chart is printed once the editor closes
+	pipe.SkipBreakdown = r.Args.Edit
+	if r.Args.MaxTokens > 0 {
+		pipe.Metrics.SetBudget(r.Args.MaxTokens)
+	}
+	if r.Args.OutputFormat == "jsonl" {
+		pipe.Metrics.RecordSections()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	var buf bytes.Buffer
-	var dest io.Writer
+	done := make(chan error, 1)
+	go func() { done <- pipe.Run(ctx, &buf) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, nil, err
+		}
+	case <-pipe.Metrics.OverBudget():
+		// Stop the render, and wait so it can't print a chart after we return
+		cancel()
+		<-done
+		return nil, nil, budgetError(pipe.Metrics)
+	}
+
+	// The final counts may only land once Run has waited on the metrics.
+	select {
+	case <-pipe.Metrics.OverBudget():
+		return nil, nil, budgetError(pipe.Metrics)
+	default:
+	}
+
+	if r.Args.OutputFormat == "jsonl" {
+		out, err := sectionsJSONL(pipe.Metrics.Sections())
+		return out, pipe, err
+	}
+	return buf.Bytes(), pipe, nil
+}
+
+// sectionsJSONL encodes the rendered sections as JSON Lines, in render order.
+// Layouts are written before the template body, so the sections follow the
+// output; a partial is a section of its own and also part of its caller.
+func sectionsJSONL(sections []metrics.Section) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range sections {
+		if err := enc.Encode(s); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// budgetError reports how far the output exceeded the --max-tokens budget.
+func budgetError(m *metrics.OutputMetrics) error {
+	return fmt.Errorf("token budget exceeded: %d > %d", m.TotalTokens(), m.Budget())
+}
+
+// emit writes rendered output to the destination chosen by --output.
+func (r *OutRunner) emit(out []byte) error {
 	switch {
 	case r.Args.Output == "-":
-		dest = os.Stdout
+		if _, err := os.Stdout.Write(out); err != nil {
+			return fmt.Errorf("failed to write output: %v", err)
+		}
 	case r.Args.Output != "":
-		file, err := os.Create(r.Args.Output)
-		if err != nil {
-			return fmt.Errorf("failed to create output file %s: %v", r.Args.Output, err)
+		if err := os.WriteFile(r.Args.Output, out, 0644); err != nil {
+			return fmt.Errorf("failed to write output file %s: %v", r.Args.Output, err)
 		}
-		defer file.Close()
-		dest = file
 	default:
-		dest = &buf
+		if err := clipboard.WriteAll(string(out)); err != nil {
+			return fmt.Errorf("failed to copy to clipboard: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "Output copied to clipboard")
 	}
+	return nil
+}
 
-	pipe, err := BuildOutPipeline(r.RootPath, r.Args)
+// watch re-renders whenever a file in the repo or the template directories
+// changes, until interrupted. The repo is watched through
+// DirectoryTree.Watch, so ignored files do not trigger renders. Output is
+// only emitted when it differs from the previous render, so an output file
+// inside a watched directory does not trigger an endless loop.
+func (r *OutRunner) watch(last []byte) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dirs := templateDirs(&AppEnv{RootPath: RootPath(r.RootPath)}, r.Args)
+	fmt.Fprintf(os.Stderr, "Watching %s for changes (Ctrl-C to stop)\n", strings.Join(dirs, ", "))
+
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default: // a render is already pending
+		}
+	}
+
+	dt := NewDirectoryTreeWithOptions(r.RootPath, directoryTreeOptions(r.Args))
+	repoChanges, err := dt.Watch(ctx)
 	if err != nil {
 		return err
 	}
+	go func() {
+		for range repoChanges {
+			notify()
+		}
+	}()
 
-	pipe.ContentSpecs = r.Args.Content
+	// dirs[0] is the repo root; the rest are template lookup directories
+	watchErr := make(chan error, 1)
+	if len(dirs) > 1 {
+		go func() { watchErr <- render.Watch(ctx, dirs[1:], notify) }()
+	}
 
-	if err := pipe.Run(dest); err != nil {
-		return err
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watchErr:
+			if err != nil {
+				return err
+			}
+		case <-changed:
+			out, pipe, err := r.render()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Render failed: %v\n", err)
+				continue
+			}
+			if bytes.Equal(out, last) {
+				continue
+			}
+			last = out
+			if err := r.emit(out); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			if err := r.writeMetrics(pipe.Metrics); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
 	}
+}
 
-	if r.Args.Output == "" {
-		if err := clipboard.WriteAll(buf.String()); err != nil {
-			return fmt.Errorf("failed to copy to clipboard: %v", err)
+// writeTreeJSON writes dt as JSON to the path given by --output-tree-json.
+func (r *OutRunner) writeTreeJSON(dt *DirectoryTree) error {
+	if r.Args.OutputTreeJSON == "" {
+		return nil
+	}
+	dt.TokenEstimator = r.TokenEstimator
+	b, err := json.MarshalIndent(dt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal directory tree: %v", err)
+	}
+	if err := os.WriteFile(r.Args.OutputTreeJSON, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write tree file %s: %v", r.Args.OutputTreeJSON, err)
+	}
+	return nil
+}
+
+// writeMetrics exports m to the destination chosen by --metrics: JSON to
+// stdout for '-', CSV for a .csv path, and JSON for any other path.
+func (r *OutRunner) writeMetrics(m *metrics.OutputMetrics) error {
+	dest := r.Args.Metrics
+	if dest == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if strings.EqualFold(filepath.Ext(dest), ".csv") {
+		if err := m.WriteCSV(&buf); err != nil {
+			return fmt.Errorf("failed to write metrics CSV: %v", err)
 		}
-		fmt.Fprintln(os.Stderr, "Output copied to clipboard")
+	} else {
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal metrics: %v", err)
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
 	}
 
+	if dest == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(dest, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file %s: %v", dest, err)
+	}
 	return nil
 }
 
@@ -167,6 +469,59 @@ func parseDataParams(params []string) (map[string]string, error) {
 	return result, nil
 }
 
+// loadData builds the .Data map from an optional data file and the CLI
+// key=value pairs. Pairs given on the command line override file values.
+func loadData(dataFile string, params []string) (map[string]string, error) {
+	result := make(map[string]string)
+	if dataFile != "" {
+		fileData, err := parseDataFile(dataFile)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(result, fileData)
+	}
+
+	cliData, err := parseDataParams(params)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(result, cliData)
+	return result, nil
+}
+
+// parseDataFile reads a flat table of key/value pairs from a .toml or .json
+// file. Non-string scalars are formatted with fmt; nested tables and arrays
+// are rejected, since .Data only holds strings.
+func parseDataFile(path string) (map[string]string, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	var raw map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		err = toml.Unmarshal(blob, &raw)
+	case ".json":
+		err = json.Unmarshal(blob, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported data file extension %q, use .toml or .json", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+	}
+
+	result := make(map[string]string, len(raw))
+	for key, val := range raw {
+		switch val.(type) {
+		case map[string]any, []any, []map[string]any:
+			return nil, fmt.Errorf("data file %s: value for %q must be a string, number or boolean", path, key)
+		}
+		result[key] = fmt.Sprint(val)
+	}
+	return result, nil
+}
+
 // calculateTokenCount calculates the total token count for a list of file paths
 func calculateTokenCount(fsys fs.FS, filePaths []string, tokenEstimator TokenEstimator) (int, error) {
 	totalTokenCount := 0
@@ -271,6 +626,18 @@ func loadVibeFiles(startPath string) (string, error) {
 	return content.String(), nil
 }
 
+// tokenEstimatorFor returns the TokenEstimator selected by --token-estimator.
+func tokenEstimatorFor(name string) (TokenEstimator, error) {
+	switch name {
+	case "tiktoken":
+		return estimateTokenCountTiktoken, nil
+	case "simple", "":
+		return estimateTokenCountSimple, nil
+	default:
+		return nil, fmt.Errorf("unknown token estimator: %s", name)
+	}
+}
+
 // estimateTokenCountSimple estimates tokens using the simple size/4 method
 func estimateTokenCountSimple(fsys fs.FS, filePath string) (int, error) {
 	data, err := fs.ReadFile(fsys, filePath)
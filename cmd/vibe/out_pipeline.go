@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"log"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -21,19 +24,24 @@ type OutPipeline struct {
 	Loader   ContentLoader
 	Env      *AppEnv
 
-	Template     *render.Template
-	ContentSpecs []string
+	Template      *render.Template
+	ContentSpecs  []string
+	MetricsFormat string // "text" (default), "svg" or "html"
+	SkipBreakdown bool   // leave printing the token chart to the caller
 }
 
 // outData implements render.Content and exposes helpers for templates.
 type outData struct {
 	pipeline         *OutPipeline
 	selectPattern    string
+	excludePattern   string
+	maxFiles         int
 	dirTreePattern   string
 	rootPath         string
 	WorkingDirectory string
 	ContentStr       string
 	Data             map[string]string
+	GitDiff          string
 
 	fileMapOnce sync.Once
 	fileMap     string
@@ -53,6 +61,22 @@ type outData struct {
 	selectionsOnce sync.Once
 	selections     []selection.FileSelection
 	selectionsErr  error
+
+	filesOnce sync.Once
+	files     []FileSelectionInfo
+	filesErr  error
+
+	clipboardOnce sync.Once
+	clipboardText string
+	clipboardErr  error
+}
+
+// FileSelectionInfo describes one selected file, for templates that build
+// their own listing with {{ range .SelectedFiles }}.
+type FileSelectionInfo struct {
+	Path     string
+	Tokens   int    // estimated with the --token-estimator counter
+	Language string // code fence language, see ExtensionToLanguage
 }
 
 func (d *outData) Content() string     { return d.ContentStr }
@@ -72,6 +96,9 @@ func (d *outData) FileMap() (string, error) {
 		var buf strings.Builder
 		d.fileMapErr = d.pipeline.FileMap.Output(&buf, sels)
 		d.fileMap = buf.String()
+		if d.fileMapErr == nil {
+			d.pipeline.Metrics.AddSection("file-map", "", d.fileMap)
+		}
 	})
 	return d.fileMap, d.fileMapErr
 }
@@ -92,6 +119,15 @@ func (d *outData) RepoPrompts() (string, error) {
 	return d.prompts, d.promptsErr
 }
 
+// ClipboardText returns the clipboard contents. The clipboard is read once
+// per render, however often the template uses {{ .ClipboardText }}.
+func (d *outData) ClipboardText() (string, error) {
+	d.clipboardOnce.Do(func() {
+		d.clipboardText, d.clipboardErr = readClipboard()
+	})
+	return d.clipboardText, d.clipboardErr
+}
+
 // getSelections returns the FileSelection slice matched by the
 // template's `select` pattern, memoised for reuse by other helpers.
 func (d *outData) getSelections() ([]selection.FileSelection, error) {
@@ -99,7 +135,12 @@ func (d *outData) getSelections() ([]selection.FileSelection, error) {
 		if d.selectPattern == "" {
 			return
 		}
-		d.selections, d.selectionsErr = d.pipeline.DT.SelectFiles(d.selectPattern)
+		d.selections, d.selectionsErr = d.pipeline.DT.SelectFiles(d.selectPattern, d.excludePattern)
+		// selections are sorted by path, so this keeps the first maxFiles paths
+		if d.maxFiles > 0 && len(d.selections) > d.maxFiles {
+			log.Printf("warning: %d files selected, keeping the first %d (max files)", len(d.selections), d.maxFiles)
+			d.selections = d.selections[:d.maxFiles]
+		}
 	})
 	return d.selections, d.selectionsErr
 }
@@ -117,20 +158,42 @@ func (d *outData) SelectedPaths() []string {
 	return d.selectedPaths
 }
 
-// Run executes the rendering pipeline using args for configuration.
-func (p *OutPipeline) Run(out io.Writer) error {
-	dataMap, err := parseDataParams(p.Env.DataPairs)
-	if err != nil {
-		return err
-	}
-
-	content := ""
-	if len(p.ContentSpecs) > 0 {
-		c, err := p.Loader.LoadSources(context.Background(), p.ContentSpecs)
+// SelectedFiles returns the selected files with their token counts. Files
+// are only read, to count their tokens, the first time this is called.
+func (d *outData) SelectedFiles() ([]FileSelectionInfo, error) {
+	d.filesOnce.Do(func() {
+		sels, err := d.getSelections()
 		if err != nil {
-			return fmt.Errorf("failed to load content: %w", err)
+			d.filesErr = err
+			return
 		}
-		content = c
+		for _, s := range sels {
+			contents, err := s.Contents()
+			if err != nil {
+				d.filesErr = fmt.Errorf("failed to read %s: %w", s.Path, err)
+				return
+			}
+			var text strings.Builder
+			for _, c := range contents {
+				text.WriteString(c.Content)
+			}
+			_, tokens, _ := d.pipeline.Metrics.Ctr.Count(text.String())
+			d.files = append(d.files, FileSelectionInfo{
+				Path:     s.Path,
+				Tokens:   tokens,
+				Language: ExtensionToLanguage(filepath.Ext(s.Path)),
+			})
+		}
+	})
+	return d.files, d.filesErr
+}
+
+// Run executes the rendering pipeline using args for configuration. Once ctx
+// is done the render stops and no token breakdown is printed.
+func (p *OutPipeline) Run(ctx context.Context, out io.Writer) error {
+	dataMap, err := loadData(p.Env.DataFile, p.Env.DataPairs)
+	if err != nil {
+		return err
 	}
 
 	tmpl := p.Template
@@ -146,24 +209,47 @@ func (p *OutPipeline) Run(out io.Writer) error {
 	data := &outData{
 		pipeline:         p,
 		selectPattern:    selectPattern,
+		excludePattern:   tmpl.FrontMatter.Exclude,
+		maxFiles:         tmpl.FrontMatter.MaxFiles,
 		dirTreePattern:   dirTreePattern,
 		rootPath:         root,
 		WorkingDirectory: string(p.Env.WorkingDirectory),
-		ContentStr:       content,
 		Data:             dataMap,
+		GitDiff:          p.Env.GitDiff,
 	}
 
-	rendered, err := p.Renderer.RenderTemplate(tmpl, data)
-	if err != nil {
+	if len(p.ContentSpecs) > 0 {
+		// template: sources render with the same data, minus .Content
+		// git: and gitdiff: sources read the repo at the root, like the selection
+		loadCtx := render.WithGitDir(render.WithTemplateRenderer(ctx, p.Renderer, data), root)
+		c, err := p.Loader.LoadSources(loadCtx, p.ContentSpecs)
+		if err != nil {
+			return fmt.Errorf("failed to load content: %w", err)
+		}
+		data.ContentStr = c
+	}
+
+	var rendered bytes.Buffer
+	if err := p.Renderer.RenderTemplateToContext(ctx, &rendered, tmpl, data); err != nil {
 		return err
 	}
 
-	if _, err := fmt.Fprint(out, rendered); err != nil {
+	if _, err := out.Write(rendered.Bytes()); err != nil {
 		return fmt.Errorf("failed to write output: %v", err)
 	}
 
 	p.Metrics.Wait()
-	if err := PrintTokenBreakdown(p.Metrics); err != nil {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Over --max-tokens the caller reports an error instead of the chart
+	if budget := p.Metrics.Budget(); budget > 0 && p.Metrics.TotalTokens() > budget {
+		return nil
+	}
+	if p.SkipBreakdown {
+		return nil
+	}
+	if err := PrintTokenBreakdownFormat(p.Metrics, p.MetricsFormat); err != nil {
 		return err
 	}
 	return nil
@@ -1,10 +1,20 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hayeah/fork2/internal/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,7 +40,7 @@ func TestOutRunner_SelectVariants(t *testing.T) {
 				TokenEstimator: "simple",
 			}
 			if strings.Contains(c.selectQ, ";") {
-				cmd.Layout = "" // Prevent automatic layout assignment for union patterns
+				cmd.Layout = nil // Prevent automatic layout assignment for union patterns
 			}
 
 			out := runRunner(t, cmd, "testdata/project")
@@ -78,6 +88,87 @@ func TestOutRunner_DataAndContent(t *testing.T) {
 		out := runRunner(t, cmd, "testdata/project")
 		assert.Contains(t, out, "Hello from test")
 	})
+
+	t.Run("content transform", func(t *testing.T) {
+		outFile := createTempOutput(t)
+
+		cmd := OutCmd{
+			Template:         "content_test.md",
+			Content:          []string{"text:Hello from test"},
+			ContentTransform: "tr a-z A-Z",
+			Output:           outFile,
+			TokenEstimator:   "simple",
+		}
+
+		out := runRunner(t, cmd, "testdata/project")
+		assert.Contains(t, out, "HELLO FROM TEST")
+	})
+}
+
+func TestOutRunner_LayoutChain(t *testing.T) {
+	for name, layouts := range map[string][]string{
+		"repeated flag":       {"layouts/outer.md", "layouts/inner.md"},
+		"semicolon separated": {"layouts/outer.md;layouts/inner.md"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := OutCmd{
+				Template:       "content_test.md",
+				Layout:         layouts,
+				Content:        []string{"text:Hello from test"},
+				Output:         createTempOutput(t),
+				TokenEstimator: "simple",
+			}
+
+			// the first layout is outermost; the template body follows the
+			// layouts, as with a front matter layout chain
+			out := runRunner(t, cmd, "testdata/project")
+			assert.Contains(t, out, "OUTER START\nINNER START\n\nINNER END\n\nOUTER END\n")
+			assert.Greater(t, strings.Index(out, "Hello from test"), strings.Index(out, "OUTER END"))
+		})
+	}
+}
+
+func TestOutRunner_DataFile(t *testing.T) {
+	t.Run("toml", func(t *testing.T) {
+		cmd := OutCmd{
+			Template:       "data_file_test.md",
+			DataFile:       "testdata/vars.toml",
+			Output:         createTempOutput(t),
+			TokenEstimator: "simple",
+		}
+
+		out := runRunner(t, cmd, "testdata/project")
+		assert.Contains(t, out, "Project: fork2\n")
+		assert.Contains(t, out, "Model: claude")
+		assert.Contains(t, out, "Retries: 3")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		cmd := OutCmd{
+			Template:       "data_file_test.md",
+			DataFile:       "testdata/vars.json",
+			Output:         createTempOutput(t),
+			TokenEstimator: "simple",
+		}
+
+		out := runRunner(t, cmd, "testdata/project")
+		assert.Contains(t, out, "Project: fork2-json")
+		assert.Contains(t, out, "Retries: 3")
+	})
+
+	t.Run("cli data overrides file", func(t *testing.T) {
+		cmd := OutCmd{
+			Template:       "data_file_test.md",
+			DataFile:       "testdata/vars.toml",
+			Data:           []string{"model=gpt4"},
+			Output:         createTempOutput(t),
+			TokenEstimator: "simple",
+		}
+
+		out := runRunner(t, cmd, "testdata/project")
+		assert.Contains(t, out, "Project: fork2\n")
+		assert.Contains(t, out, "Model: gpt4")
+	})
 }
 
 func TestOutRunner_AllFlag(t *testing.T) {
@@ -123,6 +214,18 @@ func TestOutRunner_ErrorHandling(t *testing.T) {
 			errPhase: "run",
 			errMsg:   "",
 		},
+		{
+			name: "token budget exceeded",
+			cmd: OutCmd{
+				Select:         ".go$",
+				Template:       "list_selected.md",
+				Output:         "-",
+				TokenEstimator: "simple",
+				MaxTokens:      1,
+			},
+			errPhase: "run",
+			errMsg:   "token budget exceeded",
+		},
 	}
 
 	for _, c := range cases {
@@ -143,10 +246,40 @@ func TestOutRunner_ErrorHandling(t *testing.T) {
 			require.NoError(t, err)
 			err = runner.Run()
 			assert.Error(t, err)
+			if c.errMsg != "" {
+				assert.Contains(t, err.Error(), c.errMsg)
+			}
 		})
 	}
 }
 
+func TestOutRunner_OverBudgetPrintsNoChart(t *testing.T) {
+	runner, err := NewAskRunner(OutCmd{
+		Select:         ".go$",
+		Template:       "list_selected.md",
+		Root:           "testdata/project",
+		TemplatePaths:  []string{"testdata/templates"},
+		Output:         createTempOutput(t),
+		TokenEstimator: "simple",
+		MaxTokens:      1,
+	})
+	require.NoError(t, err)
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	oldStdout := os.Stdout
+	os.Stdout = w
+	err = runner.Run()
+	// give a render left running the chance to print its chart late
+	time.Sleep(50 * time.Millisecond)
+	os.Stdout = oldStdout
+	w.Close()
+	assert.ErrorContains(t, err, "token budget exceeded")
+
+	stdout, _ := io.ReadAll(r)
+	assert.Empty(t, string(stdout))
+}
+
 func TestOutRunner_TemplateHelpers(t *testing.T) {
 	outFile := createTempOutput(t)
 
@@ -212,3 +345,372 @@ func TestOutRunner_TokenEstimator(t *testing.T) {
 		})
 	}
 }
+
+func TestOutRunner_OutputTreeJSON(t *testing.T) {
+	treeFile := filepath.Join(t.TempDir(), "tree.json")
+
+	cmd := OutCmd{
+		Template:       "list_files.md",
+		Output:         createTempOutput(t),
+		OutputTreeJSON: treeFile,
+		TokenEstimator: "simple",
+	}
+	runRunner(t, cmd, "testdata/project")
+
+	b, err := os.ReadFile(treeFile)
+	require.NoError(t, err)
+	var root treeNodeJSON
+	require.NoError(t, json.Unmarshal(b, &root))
+	assert.True(t, root.IsDir)
+	assert.NotEmpty(t, root.Children)
+}
+
+func TestProvideTemplate_ExcludePrecedence(t *testing.T) {
+	tempDir, err := createTestDirectory(t, map[string]string{
+		"prompt.md": "---toml\nselect = \"*.go\"\nexclude = \"*_test.go\"\n---\nhi",
+	})
+	require.NoError(t, err)
+
+	env := &AppEnv{RootPath: RootPath(tempDir)}
+	fsList := []fs.FS{os.DirFS(tempDir)}
+
+	tmpl, err := ProvideTemplate(env, ProvideResolver(env, fsList), OutCmd{Template: "prompt.md"}, fsList)
+	require.NoError(t, err)
+	assert.Equal(t, "*_test.go", tmpl.FrontMatter.Exclude)
+
+	tmpl, err = ProvideTemplate(env, ProvideResolver(env, fsList), OutCmd{Template: "prompt.md", Exclude: "vendor/**"}, fsList)
+	require.NoError(t, err)
+	assert.Equal(t, "vendor/**", tmpl.FrontMatter.Exclude)
+}
+
+func TestOutRunner_Edit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editor script needs a POSIX shell")
+	}
+
+	// a fake editor that rewrites the file it is given
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf 'edited by hand\\n' > \"$1\"\n"
+	require.NoError(t, os.WriteFile(editor, []byte(script), 0755))
+	t.Setenv("EDITOR", editor)
+
+	cmd := OutCmd{
+		Template:       "list_files.md",
+		Output:         createTempOutput(t),
+		Edit:           true,
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Equal(t, "edited by hand\n", out)
+
+	_, err := NewAskRunner(OutCmd{Edit: true, Watch: true})
+	assert.ErrorContains(t, err, "--edit cannot be combined with --watch")
+}
+
+func TestOutRunner_Trace(t *testing.T) {
+	trace := filepath.Join(t.TempDir(), "trace.md")
+	cmd := OutCmd{
+		Template:       "list_files.md",
+		Output:         createTempOutput(t),
+		Trace:          trace,
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.NotContains(t, out, "<!--")
+
+	traced, err := os.ReadFile(trace)
+	require.NoError(t, err)
+	assert.Contains(t, string(traced), "<!-- BEGIN template:list_files.md -->")
+	assert.Contains(t, string(traced), "<!-- END template:list_files.md -->")
+}
+
+func TestOutRunner_Stdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	oldStdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	_, err = w.WriteString("From stdin: {{ .Content }}\n")
+	require.NoError(t, err)
+	w.Close()
+
+	cmd := OutCmd{
+		Stdin:          true,
+		Layout:         []string{"layouts/outer.md"},
+		Content:        []string{"text:Hello from test"},
+		Output:         createTempOutput(t),
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "OUTER START")
+	assert.Contains(t, out, "From stdin: Hello from test\n")
+
+	_, err = NewAskRunner(OutCmd{Stdin: true, Template: "content_test.md", Root: "testdata/project"})
+	assert.ErrorContains(t, err, "--stdin cannot be combined")
+	_, err = NewAskRunner(OutCmd{Stdin: true, Content: []string{"-"}, Root: "testdata/project"})
+	assert.ErrorContains(t, err, "--stdin cannot be combined")
+	_, err = NewAskRunner(OutCmd{Stdin: true, Watch: true, Root: "testdata/project"})
+	assert.ErrorContains(t, err, "--stdin cannot be combined with --watch")
+}
+
+func TestOutRunner_PromptRequired(t *testing.T) {
+	newRunner := func(t *testing.T, data ...string) (*OutRunner, string) {
+		output := createTempOutput(t)
+		runner, err := NewAskRunner(OutCmd{
+			Template:       "require_test.md",
+			Data:           data,
+			Root:           "testdata/project",
+			TemplatePaths:  []string{"testdata/templates"},
+			Output:         output,
+			TokenEstimator: "simple",
+		})
+		require.NoError(t, err)
+		return runner, output
+	}
+
+	t.Run("prompts for missing values", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		_, err = w.WriteString("fork2\n")
+		require.NoError(t, err)
+		w.Close()
+
+		runner, output := newRunner(t, "Owner=hayeah")
+		runner.Prompt = r
+		require.NoError(t, runner.Run())
+
+		out, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "Project: fork2\nOwner: hayeah\n")
+		assert.Contains(t, runner.Args.Data, "ProjectName=fork2")
+	})
+
+	t.Run("keeps special characters", func(t *testing.T) {
+		runner, output := newRunner(t, "Owner=hayeah")
+		runner.Prompt = strings.NewReader("Tom & Jerry, a+b = 50%\n")
+		require.NoError(t, runner.Run())
+
+		out, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "Project: Tom & Jerry, a+b = 50%\n")
+	})
+
+	t.Run("fails without a terminal", func(t *testing.T) {
+		runner, _ := newRunner(t, "Owner=hayeah")
+		runner.Prompt = nil
+		err := runner.Run()
+		assert.ErrorContains(t, err, "requires Data.ProjectName; pass it with --data ProjectName=<value>")
+	})
+
+	t.Run("fails when input ends", func(t *testing.T) {
+		runner, _ := newRunner(t)
+		runner.Prompt = strings.NewReader("fork2\n")
+		err := runner.Run()
+		assert.ErrorContains(t, err, "failed to read value for Data.Owner")
+	})
+}
+
+func TestOutRunner_SelectedFiles(t *testing.T) {
+	cmd := OutCmd{
+		Select:         "=main.go;=config.yaml",
+		Template:       "selected_files.md",
+		Output:         "-",
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+
+	for path, lang := range map[string]string{"main.go": "go", "config.yaml": "yaml"} {
+		info, err := os.Stat(filepath.Join("testdata/project", path))
+		require.NoError(t, err)
+		assert.Contains(t, out, fmt.Sprintf("- %s (%s, %d tokens)\n", path, lang, info.Size()/4))
+	}
+}
+
+func TestOutRunner_MaxFiles(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	cmd := OutCmd{
+		Select:         "=main.go;=config.yaml;=go.mod",
+		Template:       "list_selected.md",
+		MaxFiles:       2,
+		Output:         "-",
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+	assertHasFiles(t, out, "config.yaml", "go.mod")
+	assert.NotContains(t, out, "- main.go")
+	assert.Contains(t, logs.String(), "3 files selected, keeping the first 2")
+
+	// max_files in front matter, overridden by the flag
+	cmd = OutCmd{Template: "max_files_test.md", Output: "-", TokenEstimator: "simple"}
+	out = runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "- config.yaml\n")
+	assert.NotContains(t, out, "- go.mod")
+
+	cmd.MaxFiles = 3
+	out = runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "- config.yaml\n- go.mod\n- main.go\n")
+}
+
+func TestOutRunner_TemplateContent(t *testing.T) {
+	cmd := OutCmd{
+		Template:       "content_test.md",
+		Content:        []string{"template:data_test.md"},
+		Data:           []string{"model=gpt4"},
+		Output:         createTempOutput(t),
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "## Content Test\n\n## Data Parameters Test\n\nModel: gpt4\n")
+}
+
+func TestOutRunner_ClipboardText(t *testing.T) {
+	calls := 0
+	orig := readClipboard
+	readClipboard = func() (string, error) {
+		calls++
+		return "pasted context", nil
+	}
+	t.Cleanup(func() { readClipboard = orig })
+
+	cmd := OutCmd{
+		Template:       "clipboard_test.md",
+		Output:         "-",
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "First: pasted context\nSecond: pasted context\n")
+	assert.Equal(t, 1, calls)
+}
+
+func TestOutRunner_OutputFormatJSONL(t *testing.T) {
+	cmd := OutCmd{
+		Select:         "=go.mod",
+		Template:       "output_format_test.md",
+		OutputFormat:   "jsonl",
+		Output:         createTempOutput(t),
+		TokenEstimator: "simple",
+	}
+	out := runRunner(t, cmd, "testdata/project")
+
+	var sections []metrics.Section
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var s metrics.Section
+		require.NoError(t, dec.Decode(&s))
+		sections = append(sections, s)
+	}
+	require.Len(t, sections, 3)
+
+	// --select wraps the template in the files layout, which renders the
+	// file map before the template body
+	assert.Equal(t, "file-map", sections[0].Type)
+	assert.Contains(t, sections[0].Content, "module github.com/example/project")
+
+	assert.Equal(t, "template", sections[1].Type)
+	assert.Equal(t, "files", sections[1].Path)
+
+	assert.Equal(t, "template", sections[2].Type)
+	assert.True(t, strings.HasSuffix(sections[2].Path, "output_format_test.md"), sections[2].Path)
+	assert.Equal(t, "Files:\n"+sections[0].Content+"\n", sections[2].Content)
+
+	cmd.OutputFormat = "yaml"
+	_, err := NewAskRunner(cmd)
+	assert.ErrorContains(t, err, `unknown output format "yaml"`)
+}
+
+func TestOutRunner_ConfigDefaults(t *testing.T) {
+	// the front matter select beats the config, while the config exclude
+	// fills the exclude the front matter left empty
+	cfg := Config{Select: "=main.go", Exclude: "=config.yaml"}
+	cmd := cfg.Apply(OutCmd{Template: "max_files_test.md", Output: "-", TokenEstimator: "simple"})
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "- go.mod\n")
+	assert.NotContains(t, out, "- main.go")
+	assert.NotContains(t, out, "- config.yaml")
+
+	// --select still beats the front matter
+	cmd.Select = "=main.go"
+	out = runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "- main.go\n")
+}
+
+func TestOutRunner_ProfileBelowFrontMatter(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := Config{Profiles: map[string]Config{
+		"docs": {Select: "=config.yaml", Layout: "missing-layout"},
+	}}
+
+	// the template's front matter select and layout beat the profile
+	cmd, err := cfg.ApplyProfile(OutCmd{Profile: "docs", Template: "with_layout.md", Output: "-", TokenEstimator: "simple"})
+	require.NoError(t, err)
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "## Analysis Request")
+	assert.Contains(t, out, "- main.go\n")
+	assert.NotContains(t, out, "- config.yaml")
+
+	// without a template, the profile's select renders the files template
+	cmd, err = cfg.ApplyProfile(OutCmd{Profile: "docs", Layout: []string{"layouts/inner.md"}, Output: "-", TokenEstimator: "simple"})
+	require.NoError(t, err)
+	out = runRunner(t, cmd, "testdata/project")
+	assertHasFiles(t, out, "config.yaml")
+	assert.NotContains(t, out, "Read File: main.go")
+}
+
+func TestOutRunner_GrepUnderRoot(t *testing.T) {
+	// testdata/project is not the working directory, so grep: must read
+	// files relative to --root
+	cmd := OutCmd{Select: "grep:Test App", Template: "list_selected.md", Output: "-", TokenEstimator: "simple"}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "- config.yaml\n")
+	assert.NotContains(t, out, "- main.go")
+}
+
+func TestOutRunner_SizeUnderRoot(t *testing.T) {
+	cmd := OutCmd{Select: "=config.yaml;=go.mod | size:>10B", Template: "list_selected.md", Output: "-", TokenEstimator: "simple"}
+	out := runRunner(t, cmd, "testdata/project")
+	assert.Contains(t, out, "- config.yaml\n")
+	assert.Contains(t, out, "- go.mod\n")
+}
+
+func TestOutRunner_Watch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VIBE_PROMPTS", "")
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "prompt.md"), []byte("version 1\n"), 0644))
+	output := filepath.Join(t.TempDir(), "out.md")
+
+	runner, err := NewAskRunner(OutCmd{Template: "prompt.md", Root: root, Output: output, Watch: true, TokenEstimator: "simple"})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- runner.Run() }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			b, _ := os.ReadFile(output)
+			return strings.Contains(string(b), want)
+		}, 5*time.Second, 20*time.Millisecond, "output never contained %q", want)
+	}
+	waitFor("version 1")
+
+	// edits are picked up through DirectoryTree.Watch
+	require.NoError(t, os.WriteFile(filepath.Join(root, "prompt.md"), []byte("version 2\n"), 0644))
+	waitFor("version 2")
+
+	self, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot interrupt the watch: %v", err)
+	}
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not stop on interrupt")
+	}
+}
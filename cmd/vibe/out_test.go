@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hayeah/fork2/internal/assert"
@@ -69,3 +70,21 @@ func TestAskRunnerData(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDataFile(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+
+	nested := filepath.Join(dir, "nested.toml")
+	a.NoError(os.WriteFile(nested, []byte("[table]\nkey = \"v\"\n"), 0644))
+	_, err := parseDataFile(nested)
+	a.ErrorContains(err, `value for "table" must be`)
+
+	yaml := filepath.Join(dir, "vars.yaml")
+	a.NoError(os.WriteFile(yaml, []byte("key: v\n"), 0644))
+	_, err = parseDataFile(yaml)
+	a.ErrorContains(err, "unsupported data file extension")
+
+	_, err = parseDataFile(filepath.Join(dir, "missing.json"))
+	a.Error(err)
+}
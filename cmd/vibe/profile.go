@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hayeah/fork2/internal/selection"
+)
+
+// ProfileCmd defines the command-line arguments for the profile subcommand
+type ProfileCmd struct {
+	Save *ProfileSaveCmd `arg:"subcommand:save" help:"Save a --select pattern as a named profile"`
+}
+
+// ProfileSaveCmd defines the command-line arguments for profile save
+type ProfileSaveCmd struct {
+	Name   string `arg:"positional,required" help:"Profile name, used as vibe out --profile NAME"`
+	Select string `arg:"-s,--select,required" help:"Select pattern to save"`
+}
+
+// ProfileRunner manages the select patterns kept in a selection.ProfileStore
+type ProfileRunner struct {
+	Args   ProfileCmd
+	Store  selection.ProfileStore
+	Output io.Writer
+}
+
+// NewProfileRunner creates and initializes a new ProfileRunner
+func NewProfileRunner(cmd ProfileCmd) (*ProfileRunner, error) {
+	if cmd.Save == nil {
+		return nil, fmt.Errorf("no profile subcommand specified, use 'save'")
+	}
+	store, err := selection.DefaultProfileStore()
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileRunner{
+		Args:   cmd,
+		Store:  store,
+		Output: os.Stdout,
+	}, nil
+}
+
+// Run executes the profile subcommand
+func (r *ProfileRunner) Run() error {
+	save := r.Args.Save
+	if err := r.Store.Save(save.Name, selection.Profile{Select: save.Select}); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(r.Output, "✅ Saved profile %q to %s\n", save.Name, r.Store.Path)
+	return err
+}
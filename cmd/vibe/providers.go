@@ -4,12 +4,14 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/hayeah/fork2/ignore"
 	"github.com/hayeah/fork2/internal/metrics"
 	"github.com/hayeah/fork2/render"
 )
@@ -28,17 +30,24 @@ type AppEnv struct {
 	RootPath         RootPath
 	WorkingDirectory WorkingDirectory
 	DataPairs        []string
+	DataFile         string
 	Mode             string
+	GitDiff          string
 }
 
 // DefaultContentLoader implements ContentLoader using render.LoadContentSources.
-type DefaultContentLoader struct{}
+// A non-empty Transform is a shell command each source is piped through.
+type DefaultContentLoader struct {
+	Transform string
+}
 
-func (DefaultContentLoader) LoadSources(ctx context.Context, specs []string) (string, error) {
-	return render.LoadContentSources(ctx, specs)
+func (l DefaultContentLoader) LoadSources(ctx context.Context, specs []string) (string, error) {
+	return render.LoadContentSourcesTransformed(ctx, specs, l.Transform)
 }
 
-func ProvideContentLoader() ContentLoader { return DefaultContentLoader{} }
+func ProvideContentLoader(args OutCmd) ContentLoader {
+	return DefaultContentLoader{Transform: args.ContentTransform}
+}
 
 // ProvideRootFS creates a filesystem abstraction for the repo root
 func ProvideRootFS(env *AppEnv) (fs.FS, error) {
@@ -55,13 +64,20 @@ func ProvideAppEnv(root string, args OutCmd) (*AppEnv, error) {
 		RootPath:         RootPath(root),
 		WorkingDirectory: WorkingDirectory(abs),
 		DataPairs:        args.Data,
+		DataFile:         args.DataFile,
 		Mode:             args.Mode,
+		GitDiff:          args.GitDiff,
 	}, nil
 }
 
 // ProvideDirectoryTreeService constructs a DirectoryTree for the given root.
-func ProvideDirectoryTreeService(env *AppEnv) (*DirectoryTree, error) {
-	return NewDirectoryTree(string(env.RootPath)), nil
+func ProvideDirectoryTreeService(env *AppEnv, args OutCmd) (*DirectoryTree, error) {
+	return NewDirectoryTreeWithOptions(string(env.RootPath), directoryTreeOptions(args)), nil
+}
+
+// directoryTreeOptions maps the out command flags to DirectoryTreeOptions.
+func directoryTreeOptions(args OutCmd) DirectoryTreeOptions {
+	return DirectoryTreeOptions{MaxDepth: args.MaxDepth, NewerThan: args.NewerThan}
 }
 
 // ProvideMetrics constructs OutputMetrics with the given counter.
@@ -69,8 +85,8 @@ func ProvideMetrics(counter metrics.Counter) *metrics.OutputMetrics {
 	return metrics.NewOutputMetrics(counter, runtime.NumCPU())
 }
 
-func ProvideFileMapService(env *AppEnv, rfs fs.FS, m *metrics.OutputMetrics) *FileMapWriter {
-	return NewWriteFileMap(rfs, string(env.RootPath), m)
+func ProvideFileMapService(env *AppEnv, rfs fs.FS, m *metrics.OutputMetrics, args OutCmd) *FileMapWriter {
+	return NewWriteFileMapWithOptions(rfs, string(env.RootPath), m, FileMapOptions{CodeFences: args.CodeFences})
 }
 
 func ProvideRenderer(resolver *render.Resolver, m *metrics.OutputMetrics) *render.Renderer {
@@ -78,23 +94,34 @@ func ProvideRenderer(resolver *render.Resolver, m *metrics.OutputMetrics) *rende
 }
 
 func ProvideTemplate(env *AppEnv, resolver *render.Resolver, args OutCmd, fsList []fs.FS) (*render.Template, error) {
-	// decide which file the user wants to render
-	templatePath := args.Template
-	if templatePath == "" && args.Select != "" {
-		templatePath = "files"
-	}
-	if templatePath == "" {
-		return nil, nil // nothing to inspect
-	}
+	var tmpl *render.Template
+	if args.Stdin {
+		body, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template from stdin: %w", err)
+		}
+		// anonymous template: no front matter, no directory for ./ partials
+		tmpl = &render.Template{Path: "<stdin>", Body: string(body)}
+	} else {
+		// decide which file the user wants to render
+		templatePath := args.Template
+		if templatePath == "" && args.Select != "" {
+			templatePath = "files"
+		}
+		if templatePath == "" {
+			return nil, nil // nothing to inspect
+		}
 
-	// templatePath = strings.TrimPrefix(templatePath, "./")
+		// templatePath = strings.TrimPrefix(templatePath, "./")
 
-	// Create a temporary resolver to load the template
-	// tempResolver := render.NewResolver(env.Mode, fsList...)
-	// renderer := render.NewRenderer(tempResolver, nil)
-	tmpl, err := resolver.LoadTemplate(templatePath, nil)
-	if err != nil {
-		return nil, err
+		// Create a temporary resolver to load the template
+		// tempResolver := render.NewResolver(env.Mode, fsList...)
+		// renderer := render.NewRenderer(tempResolver, nil)
+		var err error
+		tmpl, err = resolver.LoadTemplate(templatePath, nil)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if env.Mode == "" && tmpl.FrontMatter.Mode != "" {
@@ -102,16 +129,33 @@ func ProvideTemplate(env *AppEnv, resolver *render.Resolver, args OutCmd, fsList
 	}
 
 	// Apply command-line overrides
-	if args.Layout != "" {
-		tmpl.FrontMatter.Layout = args.Layout
+	if layout := joinLayouts(args.Layout); layout != "" {
+		tmpl.FrontMatter.Layout = layout
 	}
 	if args.Select != "" {
 		tmpl.FrontMatter.Select = args.Select
 	}
+	if args.Exclude != "" {
+		tmpl.FrontMatter.Exclude = args.Exclude
+	}
+	if args.MaxFiles > 0 {
+		tmpl.FrontMatter.MaxFiles = args.MaxFiles
+	}
 	if args.SelectDirTree != "" {
 		tmpl.FrontMatter.Dirtree = args.SelectDirTree
 	}
 
+	// Config defaults only fill what the front matter left empty
+	setDefault := func(dst *string, v string) {
+		if *dst == "" {
+			*dst = v
+		}
+	}
+	setDefault(&tmpl.FrontMatter.Layout, args.Defaults.Layout)
+	setDefault(&tmpl.FrontMatter.Select, args.Defaults.Select)
+	setDefault(&tmpl.FrontMatter.Exclude, args.Defaults.Exclude)
+	setDefault(&tmpl.FrontMatter.Dirtree, args.Defaults.Dirtree)
+
 	if tmpl.FrontMatter.Layout == "" && tmpl.FrontMatter.Select != "" {
 		tmpl.FrontMatter.Layout = "files"
 	}
@@ -119,6 +163,20 @@ func ProvideTemplate(env *AppEnv, resolver *render.Resolver, args OutCmd, fsList
 	return tmpl, nil
 }
 
+// joinLayouts merges repeated --layout values, each of which may itself be
+// a ';'-separated chain, into a single front matter layout value.
+func joinLayouts(layouts []string) string {
+	var parts []string
+	for _, l := range layouts {
+		for _, p := range strings.Split(l, ";") {
+			if p = strings.TrimSpace(p); p != "" {
+				parts = append(parts, p)
+			}
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
 func ProvideResolver(env *AppEnv, fsList []fs.FS) *render.Resolver {
 	// NOTE: ProvideTemplate will destructively update some of the resolver
 	// properties depending on template metadata.
@@ -141,13 +199,51 @@ func ProvideCounter(args OutCmd) (metrics.Counter, error) {
 
 // ProvideFSList builds the filesystem stack for templates.
 func ProvideFSList(env *AppEnv, args OutCmd) ([]fs.FS, error) {
-	root := string(env.RootPath)
-	partials := []fs.FS{os.DirFS(root)}
+	var partials []fs.FS
+	for _, dir := range templateDirs(env, args) {
+		partials = append(partials, os.DirFS(dir))
+	}
+
+	systemFS, err := fs.Sub(systemTemplatesFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create system prompts fs: %v", err)
+	}
+	partials = append(partials, systemFS)
+
+	return partials, nil
+}
+
+// templateResolver returns a Resolver over the template lookup paths of root.
+// Listing its templates honours the .gitignore and .vibeIgnore of each
+// on-disk directory, so that e.g. node_modules is not searched.
+func templateResolver(root string) (*render.Resolver, error) {
+	env := &AppEnv{RootPath: RootPath(root)}
+	fsList, err := ProvideFSList(env, OutCmd{})
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := render.NewResolver("", fsList...)
+	// ProvideFSList keeps the order of templateDirs, builtins last
+	for _, dir := range templateDirs(env, OutCmd{}) {
+		ig, err := ignore.NewIgnore(dir)
+		if err != nil {
+			return nil, err
+		}
+		resolver.Ignores = append(resolver.Ignores, ig)
+	}
+	return resolver, nil
+}
+
+// templateDirs returns the on-disk directories searched for templates, in
+// priority order. The builtin system templates are not included.
+func templateDirs(env *AppEnv, args OutCmd) []string {
+	dirs := []string{string(env.RootPath)}
 
 	// Add any additional template paths from args
 	for _, path := range args.TemplatePaths {
 		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
-			partials = append(partials, os.DirFS(path))
+			dirs = append(dirs, path)
 		}
 	}
 
@@ -158,7 +254,7 @@ func ProvideFSList(env *AppEnv, args OutCmd) ([]fs.FS, error) {
 				continue
 			}
 			if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
-				partials = append(partials, os.DirFS(dir))
+				dirs = append(dirs, dir)
 			}
 		}
 	}
@@ -166,15 +262,9 @@ func ProvideFSList(env *AppEnv, args OutCmd) ([]fs.FS, error) {
 	if home, err := os.UserHomeDir(); err == nil {
 		userVibe := filepath.Join(home, ".vibe")
 		if fi, err := os.Stat(userVibe); err == nil && fi.IsDir() {
-			partials = append(partials, os.DirFS(userVibe))
+			dirs = append(dirs, userVibe)
 		}
 	}
 
-	systemFS, err := fs.Sub(systemTemplatesFS, "templates")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create system prompts fs: %v", err)
-	}
-	partials = append(partials, systemFS)
-
-	return partials, nil
+	return dirs
 }
@@ -17,7 +17,7 @@ func BuildOutPipeline(root string, args OutCmd) (*OutPipeline, error) {
 	if err != nil {
 		return nil, err
 	}
-	directoryTree, err := ProvideDirectoryTreeService(appEnv)
+	directoryTree, err := ProvideDirectoryTreeService(appEnv, args)
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +36,8 @@ func BuildOutPipeline(root string, args OutCmd) (*OutPipeline, error) {
 	if err != nil {
 		return nil, err
 	}
-	fileMapWriter := ProvideFileMapService(appEnv, fs, outputMetrics)
-	contentLoader := ProvideContentLoader()
+	fileMapWriter := ProvideFileMapService(appEnv, fs, outputMetrics, args)
+	contentLoader := ProvideContentLoader(args)
 	template, err := ProvideTemplate(appEnv, resolver, args, v)
 	if err != nil {
 		return nil, err
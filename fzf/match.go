@@ -4,6 +4,7 @@ package fzf
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -111,6 +112,86 @@ NextPath:
 	return out, nil
 }
 
+// RankedMatch is a path kept by MatchRanked together with its relevance score.
+type RankedMatch struct {
+	Path  string
+	Score int
+}
+
+// score weights used by MatchRanked; every positive term adds up to the sum
+const (
+	scoreMatch         = 16 // the term occurs in the path
+	scoreWordBoundary  = 8  // the occurrence starts at a word boundary
+	scoreBasename      = 8  // the occurrence lies in the last path segment
+	scoreExactBasename = 32 // the term is the whole last segment (or whole path)
+)
+
+// MatchRanked filters paths like Match and scores each kept path. Results are
+// sorted by score, highest first; equal scores keep their input order. The
+// score is deterministic: a term scores higher when it starts at a word
+// boundary, sits in the file name, or is the whole file name. Negated terms
+// only filter. An empty pattern returns every path with score 0, in order.
+func (m Matcher) MatchRanked(paths []string) ([]RankedMatch, error) {
+	matched, err := m.Match(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]RankedMatch, len(matched))
+	for i, path := range matched {
+		normal := strings.ToLower(filepath.ToSlash(path))
+		score := 0
+		for _, term := range m.terms {
+			if !term.neg {
+				score += termScore(term, normal)
+			}
+		}
+		ranked[i] = RankedMatch{Path: path, Score: score}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked, nil
+}
+
+// termScore returns the best score over the occurrences of t in path that
+// satisfy its anchors and word-boundary rules, or 0 if there are none.
+func termScore(t advTerm, path string) int {
+	base := strings.LastIndex(path, "/") + 1
+	best := 0
+	for idx := 0; idx <= len(path)-len(t.text); idx++ {
+		if !strings.HasPrefix(path[idx:], t.text) {
+			continue
+		}
+		if t.anchorHead && idx != 0 {
+			break
+		}
+		end := idx + len(t.text)
+		if t.anchorTail && end != len(path) {
+			continue
+		}
+		leftOK := idx == 0 || !isWordChar(rune(path[idx-1]))
+		if (t.wordPrefix || t.wordExact) && !leftOK {
+			continue
+		}
+		if t.wordExact && !hasWordBoundary(path, idx, len(t.text)) {
+			continue
+		}
+
+		score := scoreMatch
+		if leftOK {
+			score += scoreWordBoundary
+		}
+		if idx >= base {
+			score += scoreBasename
+			if idx == base && end == len(path) {
+				score += scoreExactBasename
+			}
+		}
+		best = max(best, score)
+	}
+	return best
+}
+
 // -----------------------------------------------------------------------------
 // helpers
 // -----------------------------------------------------------------------------
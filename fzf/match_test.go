@@ -173,3 +173,56 @@ func TestHasWordBoundary(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchRanked(t *testing.T) {
+	assert := assert.New(t)
+
+	paths := []string{
+		"docs/selection-guide.md",
+		"internal/unselected.go",
+		"cmd/vibe/select.go",
+		"select",
+		"cmd/select/main.go",
+	}
+
+	m, err := NewMatcher("select")
+	assert.NoError(err)
+	ranked, err := m.MatchRanked(paths)
+	assert.NoError(err)
+
+	var order []string
+	for _, r := range ranked {
+		order = append(order, r.Path)
+	}
+	assert.Equal([]string{
+		"select",                  // whole file name
+		"docs/selection-guide.md", // word start in the file name...
+		"cmd/vibe/select.go",      // ...ties keep input order
+		"internal/unselected.go",  // mid-word in the file name...
+		"cmd/select/main.go",      // ...scores the same as a word start in a directory
+	}, order)
+	assert.Equal(64, ranked[0].Score)
+	assert.Equal(32, ranked[1].Score)
+
+	// negated terms filter but do not score
+	m, err = NewMatcher("select !main")
+	assert.NoError(err)
+	ranked, err = m.MatchRanked(paths)
+	assert.NoError(err)
+	assert.Equal(4, len(ranked))
+	assert.Equal(RankedMatch{Path: "select", Score: 64}, ranked[0])
+}
+
+func TestMatchRankedEmptyPattern(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := NewMatcher("")
+	assert.NoError(err)
+	ranked, err := m.MatchRanked(samplePaths)
+	assert.NoError(err)
+
+	assert.Equal(len(samplePaths), len(ranked))
+	for i, r := range ranked {
+		assert.Equal(RankedMatch{Path: samplePaths[i]}, r)
+	}
+}
@@ -3,6 +3,7 @@ package ignore
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -13,11 +14,24 @@ import (
 // Ignore encapsulates gitignore pattern matching functionality
 type Ignore struct {
 	matcher  gitignore.Matcher
+	patterns []gitignore.Pattern
 	rootPath string
 }
 
-// NewIgnore creates a new Ignore instance for the given root path
+// VibeIgnoreFile is the project-specific ignore file read next to .gitignore
+const VibeIgnoreFile = ".vibeIgnore"
+
+// NewIgnore creates a new Ignore instance for the given root path. Patterns
+// from a .vibeIgnore file in the root are applied on top of .gitignore.
 func NewIgnore(rootPath string) (*Ignore, error) {
+	return NewIgnoreWithExtra(rootPath, []string{VibeIgnoreFile})
+}
+
+// NewIgnoreWithExtra creates an Ignore instance that combines the gitignore
+// patterns with those in each of extraFiles, read from the root path. The
+// extra files use .gitignore syntax and take precedence over it. Missing
+// extra files are skipped.
+func NewIgnoreWithExtra(rootPath string, extraFiles []string) (*Ignore, error) {
 	// Create a filesystem for the directory
 	fs := osfs.New(rootPath)
 	// Read gitignore patterns
@@ -26,15 +40,73 @@ func NewIgnore(rootPath string) (*Ignore, error) {
 		return nil, fmt.Errorf("failed to read gitignore patterns: %w", err)
 	}
 
+	for _, name := range extraFiles {
+		extra, err := readPatternFile(filepath.Join(rootPath, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ignore patterns from %s: %w", name, err)
+		}
+		patterns = append(patterns, extra...)
+	}
+
 	// Create a Matcher that can check if a file or directory is ignored
 	matcher := gitignore.NewMatcher(patterns)
 
 	return &Ignore{
 		matcher:  matcher,
+		patterns: patterns,
 		rootPath: rootPath,
 	}, nil
 }
 
+// NewIgnoreFromPatterns creates an Ignore instance from patterns in
+// .gitignore syntax only; no ignore files are read.
+func NewIgnoreFromPatterns(rootPath string, patterns []string) (*Ignore, error) {
+	ig := &Ignore{
+		matcher:  gitignore.NewMatcher(nil),
+		rootPath: rootPath,
+	}
+	for _, p := range patterns {
+		if err := ig.AddPattern(p); err != nil {
+			return nil, err
+		}
+	}
+	return ig, nil
+}
+
+// AddPattern adds a pattern in .gitignore syntax, taking precedence over
+// the patterns already loaded. It must not be called while the Ignore is in
+// use by another goroutine.
+func (ig *Ignore) AddPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" || strings.HasPrefix(pattern, "#") {
+		return fmt.Errorf("invalid ignore pattern %q", pattern)
+	}
+	ig.patterns = append(ig.patterns, gitignore.ParsePattern(pattern, nil))
+	ig.matcher = gitignore.NewMatcher(ig.patterns)
+	return nil
+}
+
+// readPatternFile parses a file in .gitignore syntax. A missing file yields
+// no patterns.
+func readPatternFile(path string) ([]gitignore.Pattern, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []gitignore.Pattern
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	return patterns, nil
+}
+
 // IsIgnored checks if a path should be ignored according to gitignore rules
 func (ig *Ignore) IsIgnored(path string, isDir bool) (bool, error) {
 	// Skip .git directory
@@ -48,13 +120,23 @@ func (ig *Ignore) IsIgnored(path string, isDir bool) (bool, error) {
 		return false, err
 	}
 
+	return ig.IsIgnoredRel(filepath.ToSlash(relPath), isDir), nil
+}
+
+// IsIgnoredRel is like IsIgnored for a slash-separated path relative to the
+// root, such as a path in an fs.FS of the root directory.
+func (ig *Ignore) IsIgnoredRel(relPath string, isDir bool) bool {
+	// Skip .git directory
+	if isDir && path.Base(relPath) == ".git" {
+		return true
+	}
+
 	// Skip the root directory
 	if relPath == "." {
-		return false, nil
+		return false
 	}
 
-	parts := strings.Split(relPath, string(os.PathSeparator))
-	return ig.matcher.Match(parts, isDir), nil
+	return ig.matcher.Match(strings.Split(relPath, "/"), isDir)
 }
 
 // WalkDir walks the file tree rooted at root, calling fn for each file or
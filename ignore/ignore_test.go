@@ -0,0 +1,126 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+// walkFiles returns the root-relative paths of the files ig.WalkDir visits.
+func walkFiles(t *testing.T, ig *Ignore, root string) []string {
+	t.Helper()
+	var files []string
+	err := ig.WalkDir(root, func(path string, d os.DirEntry, isDir bool) error {
+		if !isDir {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewIgnore_VibeIgnore(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":     "build/\n",
+		".vibeIgnore":    "# logs are noise\n*.log\n",
+		"main.go":        "package main",
+		"debug.log":      "log",
+		"sub/trace.log":  "log",
+		"sub/keep.txt":   "keep",
+		"build/out.bin":  "bin",
+		"build/build.go": "package build",
+	})
+
+	ig, err := NewIgnore(root)
+	assert.NoError(err)
+	assert.Equal([]string{".gitignore", ".vibeIgnore", "main.go", "sub/keep.txt"}, walkFiles(t, ig, root))
+}
+
+func TestNewIgnoreWithExtra(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":  "*.tmp\n",
+		".aiignore":   "secrets/\n",
+		".vibeIgnore": "*.log\n",
+		"a.tmp":       "tmp",
+		"a.log":       "log",
+		"secrets/key": "key",
+		"main.go":     "package main",
+	})
+
+	// only the named extra files are read, and missing ones are skipped
+	ig, err := NewIgnoreWithExtra(root, []string{".aiignore", ".missing"})
+	assert.NoError(err)
+	assert.Equal([]string{".aiignore", ".gitignore", ".vibeIgnore", "a.log", "main.go"}, walkFiles(t, ig, root))
+}
+
+func TestIgnore_AddPattern(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":   "*.tmp\n",
+		"a.tmp":        "tmp",
+		"a.go":         "package a",
+		"gen/gen.go":   "package gen",
+		"docs/keep.md": "keep",
+	})
+
+	ig, err := NewIgnore(root)
+	assert.NoError(err)
+	assert.NoError(ig.AddPattern("gen/"))
+	// later patterns win, so a negation can re-include a gitignored file
+	assert.NoError(ig.AddPattern("!a.tmp"))
+	assert.Error(ig.AddPattern("  "))
+
+	assert.Equal([]string{".gitignore", "a.go", "a.tmp", "docs/keep.md"}, walkFiles(t, ig, root))
+}
+
+func TestNewIgnoreFromPatterns(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore": "*.go\n",
+		"a.go":       "package a",
+		"b.txt":      "b",
+		"c.log":      "c",
+	})
+
+	// the .gitignore on disk is not consulted
+	ig, err := NewIgnoreFromPatterns(root, []string{"*.log"})
+	assert.NoError(err)
+	assert.Equal([]string{".gitignore", "a.go", "b.txt"}, walkFiles(t, ig, root))
+
+	_, err = NewIgnoreFromPatterns(root, []string{""})
+	assert.Error(err)
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hayeah/fork2/internal/metrics"
 )
@@ -56,7 +57,7 @@ type fileToken struct {
 }
 
 func collectFileTokens(m *metrics.OutputMetrics) ([]fileToken, int, int) {
-	m.Wait() // ensure workers finished
+	m.Flush() // ensure pending jobs are counted
 	var (
 		out         []fileToken
 		totalTokens int
@@ -165,9 +166,10 @@ func collapseSmallDirs(root *dirNode, total int, thresholdPct float64) []bucket
 // ---------- Step ❹: merge with template/user/final totals -----------------
 
 type entry struct {
-	Label  string
-	Tokens int
-	Pct    float64
+	Label    string
+	Tokens   int
+	Pct      float64
+	Duration time.Duration // render time; 0 if not timed
 }
 
 func mergeWithExtraMetrics(buckets []bucket, m *metrics.OutputMetrics, total int) []entry {
@@ -184,9 +186,10 @@ func mergeWithExtraMetrics(buckets []bucket, m *metrics.OutputMetrics, total int
 			continue
 		}
 		out = append(out, entry{
-			Label:  k.String(),
-			Tokens: v.Tokens,
-			Pct:    pct(v.Tokens, total),
+			Label:    k.String(),
+			Tokens:   v.Tokens,
+			Pct:      pct(v.Tokens, total),
+			Duration: v.Duration,
 		})
 	}
 	return out
@@ -210,6 +213,27 @@ func layoutChart(entries []entry, total, fileCount int, opt Options) []string {
 		barW = min(barW, 30)
 	}
 	keyW := opt.TermWidth() - (barW + pctW + tokensW + gapW*3)
+
+	// The timing column only appears when some entry was timed
+	timeW := 0
+	for _, e := range entries {
+		if e.Duration > 0 {
+			timeW = 9
+			keyW -= timeW + gapW
+			break
+		}
+	}
+	timing := func(d time.Duration) string {
+		if timeW == 0 {
+			return ""
+		}
+		cell := ""
+		if d > 0 {
+			cell = fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+		}
+		return fmt.Sprintf("%*s  ", timeW, cell)
+	}
+
 	if keyW < 8 {
 		keyW = 8
 	}
@@ -241,12 +265,12 @@ func layoutChart(entries []entry, total, fileCount int, opt Options) []string {
 		}
 		bar := strings.Repeat(fill, barLen)
 		label := trim(e.Label, keyW)
-		lines = append(lines, fmt.Sprintf("%-*s  %5.1f%%  %*d  %-*s",
-			barW, bar, e.Pct, tokensW, e.Tokens, keyW, label))
+		lines = append(lines, fmt.Sprintf("%-*s  %5.1f%%  %*d  %s%-*s",
+			barW, bar, e.Pct, tokensW, e.Tokens, timing(e.Duration), keyW, label))
 	}
 
-	lines = append(lines, fmt.Sprintf("%-*s  %5.1f%%  %*d  %-*s",
-		barW, sep, 100.0, tokensW, total, keyW, "TOTAL"))
+	lines = append(lines, fmt.Sprintf("%-*s  %5.1f%%  %*d  %s%-*s",
+		barW, sep, 100.0, tokensW, total, timing(0), keyW, "TOTAL"))
 	lines = append(lines, fmt.Sprintf("\nSummary: %d files, %d tokens", fileCount, total))
 
 	return lines
@@ -2,6 +2,7 @@ package chart
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hayeah/fork2/internal/assert"
 	"github.com/hayeah/fork2/internal/metrics"
@@ -132,3 +133,29 @@ func TestLayoutChartProperties(t *testing.T) {
 	ass.Contains(lines[0], "#", "bar chars missing")
 	ass.Contains(lines[len(lines)-1], "Summary:", "summary line missing")
 }
+
+func TestLayoutChartTiming(t *testing.T) {
+	ass := assert.New(t)
+
+	opt := Options{
+		BarWidth:  20,
+		FillRune:  '#',
+		TermWidth: constantTermWidth(80),
+	}
+	entries := []entry{
+		{Label: "a.go", Tokens: 900, Pct: 90},
+		{Label: "template:main.md", Tokens: 100, Pct: 10, Duration: 1500 * time.Microsecond},
+	}
+	lines := layoutChart(entries, 1000, 1, opt)
+
+	// smallest first: the template, then the file
+	ass.Contains(lines[0], "1.5ms  template:main.md")
+	ass.NotContains(lines[1], "ms")
+	for _, ln := range lines[:3] {
+		ass.LessOrEqual(len([]rune(ln)), 80, "line too wide: %q", ln)
+	}
+
+	// Without timings the column is left out
+	lines = layoutChart([]entry{{Label: "a.go", Tokens: 900, Pct: 100}}, 900, 1, opt)
+	ass.NotContains(lines[0], "ms")
+}
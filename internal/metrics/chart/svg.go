@@ -0,0 +1,117 @@
+package chart
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hayeah/fork2/internal/metrics"
+)
+
+// SVG layout, in pixels.
+const (
+	svgLabelW   = 260
+	svgBarW     = 300 // used when opt.BarWidth is 0
+	svgStatsW   = 120
+	svgRowH     = 20
+	svgPad      = 10
+	svgFontSize = 12
+)
+
+// PrintSVG writes the token breakdown as a horizontal SVG bar chart to w.
+// Bars are proportional to the largest bucket; opt.BarWidth sets the full
+// bar length in pixels. opt.Writer and opt.TermWidth are ignored.
+func PrintSVG(m *metrics.OutputMetrics, opt Options, w io.Writer) error {
+	files, total, fileCount := collectFileTokens(m)
+	root := buildDirTree(files)
+	buckets := collapseSmallDirs(root, total, opt.ThresholdPct)
+	entries := mergeWithExtraMetrics(buckets, m, total)
+	_, err := io.WriteString(w, layoutSVG(entries, total, fileCount, opt))
+	return err
+}
+
+// PrintHTML writes a minimal standalone HTML page embedding the SVG chart.
+func PrintHTML(m *metrics.OutputMetrics, opt Options, w io.Writer) error {
+	if _, err := io.WriteString(w, htmlHeader); err != nil {
+		return err
+	}
+	if err := PrintSVG(m, opt, w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, htmlFooter)
+	return err
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Token breakdown</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+svg text { font-family: monospace; }
+svg .bar { fill: #4c78a8; }
+svg .total { font-weight: bold; }
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `</body>
+</html>
+`
+
+func layoutSVG(entries []entry, total, fileCount int, opt Options) string {
+	barW := opt.BarWidth
+	if barW <= 0 {
+		barW = svgBarW
+	}
+
+	// Largest first reads naturally top to bottom
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Tokens != entries[j].Tokens {
+			return entries[i].Tokens > entries[j].Tokens
+		}
+		return entries[i].Label < entries[j].Label
+	})
+
+	maxTokens := 0
+	for _, e := range entries {
+		maxTokens = max(maxTokens, e.Tokens)
+	}
+
+	width := svgPad*2 + svgLabelW + barW + svgStatsW
+	height := svgPad*2 + svgRowH*(len(entries)+1)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-size="%d">`+"\n",
+		width, height, svgFontSize)
+
+	barX := svgPad + svgLabelW
+	statsX := barX + barW + svgPad
+	for i, e := range entries {
+		y := svgPad + i*svgRowH
+		textY := y + svgRowH - 6
+
+		barLen := 0
+		if maxTokens > 0 {
+			barLen = int(float64(e.Tokens)/float64(maxTokens)*float64(barW) + 0.5)
+		}
+		if barLen == 0 && e.Tokens > 0 {
+			barLen = 1
+		}
+
+		fmt.Fprintf(&b, `  <text x="%d" y="%d">%s</text>`+"\n", svgPad, textY, html.EscapeString(e.Label))
+		fmt.Fprintf(&b, `  <rect class="bar" x="%d" y="%d" width="%d" height="%d" fill="#4c78a8"/>`+"\n",
+			barX, y+3, barLen, svgRowH-6)
+		fmt.Fprintf(&b, `  <text x="%d" y="%d">%.1f%% %d</text>`+"\n", statsX, textY, e.Pct, e.Tokens)
+	}
+
+	y := svgPad + len(entries)*svgRowH + svgRowH - 6
+	fmt.Fprintf(&b, `  <text class="total" x="%d" y="%d">TOTAL: %d files, %d tokens</text>`+"\n",
+		svgPad, y, fileCount, total)
+	b.WriteString("</svg>\n")
+	return b.String()
+}
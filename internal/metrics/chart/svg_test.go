@@ -0,0 +1,46 @@
+package chart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+	"github.com/hayeah/fork2/internal/metrics"
+)
+
+func TestPrintSVG(t *testing.T) {
+	ass := assert.New(t)
+
+	m := fakeMetrics()
+	m.Items[metrics.NewKey("template", "<a&b>")] = metrics.MetricItem{Tokens: 10}
+
+	var buf bytes.Buffer
+	opt := DefaultOptions(constantTermWidth(80), nil)
+	opt.BarWidth = 100
+	ass.NoError(PrintSVG(m, opt, &buf))
+	out := buf.String()
+
+	ass.True(strings.HasPrefix(out, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	ass.True(strings.HasSuffix(out, "</svg>\n"))
+
+	// the largest bucket gets the full bar and comes first
+	ass.Contains(out, `<text x="10" y="24">a/big.go</text>`)
+	ass.Contains(out, `width="100" height="14"`)
+	// labels are escaped
+	ass.Contains(out, "template:&lt;a&amp;b&gt;")
+	ass.Contains(out, "TOTAL: 3 files, 1010 tokens")
+}
+
+func TestPrintHTML(t *testing.T) {
+	ass := assert.New(t)
+
+	var buf bytes.Buffer
+	ass.NoError(PrintHTML(fakeMetrics(), DefaultOptions(constantTermWidth(80), nil), &buf))
+	out := buf.String()
+
+	ass.True(strings.HasPrefix(out, "<!DOCTYPE html>"))
+	ass.Contains(out, "<style>")
+	ass.Contains(out, "<svg ")
+	ass.True(strings.HasSuffix(out, "</html>\n"))
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TokenPrice is the price of a model in USD per million tokens
+type TokenPrice struct {
+	InputCostPer1M  float64
+	OutputCostPer1M float64
+}
+
+// PriceTable maps model names to their token prices
+type PriceTable map[string]TokenPrice
+
+// Models returns the model names in the table, sorted
+func (t PriceTable) Models() []string {
+	models := make([]string, 0, len(t))
+	for name := range t {
+		models = append(models, name)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// DefaultPriceTables returns list prices for common models
+func DefaultPriceTables() PriceTable {
+	return PriceTable{
+		"gpt-4o":            {InputCostPer1M: 2.50, OutputCostPer1M: 10.00},
+		"gpt-4o-mini":       {InputCostPer1M: 0.15, OutputCostPer1M: 0.60},
+		"o3-mini":           {InputCostPer1M: 1.10, OutputCostPer1M: 4.40},
+		"claude-3-5-sonnet": {InputCostPer1M: 3.00, OutputCostPer1M: 15.00},
+		"claude-3-5-haiku":  {InputCostPer1M: 0.80, OutputCostPer1M: 4.00},
+		"gemini-1.5-pro":    {InputCostPer1M: 1.25, OutputCostPer1M: 5.00},
+	}
+}
+
+// CostEstimate is the estimated price of sending the output to a model
+type CostEstimate struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int // not known before the call; 0 unless set by the caller
+	EstimatedUSD float64
+}
+
+// EstimateCost prices the metrics' total tokens as input to model.
+// It flushes pending jobs before reading the total.
+func EstimateCost(m *OutputMetrics, model string, table PriceTable) (CostEstimate, error) {
+	price, ok := table[model]
+	if !ok {
+		return CostEstimate{}, fmt.Errorf("no price for model %q", model)
+	}
+
+	m.Flush()
+	est := CostEstimate{
+		Model:       model,
+		InputTokens: m.TotalTokens(),
+	}
+	est.EstimatedUSD = float64(est.InputTokens)*price.InputCostPer1M/1e6 +
+		float64(est.OutputTokens)*price.OutputCostPer1M/1e6
+	return est, nil
+}
@@ -1,9 +1,15 @@
 package metrics
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 )
 
 // MetricKey identifies a specific metric by type and key
@@ -27,13 +33,17 @@ type MetricItem struct {
 	Bytes  int `json:"bytes"`
 	Tokens int `json:"tokens"`
 	Lines  int `json:"lines"`
+
+	// Duration is the render time of a template, including the partials it calls
+	Duration time.Duration `json:"duration_ns,omitempty"`
 }
 
 // job represents a pending metrics calculation job
 type job struct {
-	typ     string
-	key     string
-	content []byte
+	typ      string
+	key      string
+	content  []byte
+	duration time.Duration
 }
 
 // OutputMetrics collects metrics for various components
@@ -42,8 +52,28 @@ type OutputMetrics struct {
 	wg        sync.WaitGroup
 	jobs      chan job
 	closeOnce sync.Once
+	sendMu    sync.RWMutex // held for reading while sending to jobs, for writing to close it
+	closed    bool         // set once jobs is closed, guarded by sendMu
+	pending   int          // jobs added but not yet counted, guarded by mu
+	idle      *sync.Cond   // broadcast when pending drops to 0, uses mu
 	Items     map[MetricKey]MetricItem
 	Ctr       Counter // token/line/byte counter
+
+	total      int           // running token total, guarded by mu
+	budget     int           // max tokens, 0 = unlimited
+	overBudget chan struct{} // signalled once when total exceeds budget
+	overOnce   sync.Once
+
+	recordSections bool      // set by RecordSections, guarded by mu
+	sections       []Section // guarded by mu
+}
+
+// Section is one piece of rendered output, such as a template's output or
+// the file map. Sections are kept in the order they were added.
+type Section struct {
+	Type    string `json:"type"` // "file-map" | "template" | "include"
+	Path    string `json:"path,omitempty"`
+	Content string `json:"content"`
 }
 
 // NewOutputMetrics creates a new OutputMetrics with the given counter and worker count
@@ -53,10 +83,12 @@ func NewOutputMetrics(counter Counter, workers int) *OutputMetrics {
 	}
 
 	m := &OutputMetrics{
-		jobs:  make(chan job, workers*2), // Buffer the channel
-		Items: make(map[MetricKey]MetricItem),
-		Ctr:   counter,
+		jobs:       make(chan job, workers*2), // Buffer the channel
+		Items:      make(map[MetricKey]MetricItem),
+		Ctr:        counter,
+		overBudget: make(chan struct{}, 1),
 	}
+	m.idle = sync.NewCond(&m.mu)
 
 	// Start worker goroutines
 	m.wg.Add(workers)
@@ -72,29 +104,85 @@ func (m *OutputMetrics) worker() {
 	defer m.wg.Done()
 
 	for job := range m.jobs {
-		// Process the job
-		text := string(job.content)
-		bytes, tokens, lines := m.Ctr.Count(text)
-
-		// Update the metrics
-		m.mu.Lock()
-		key := MetricKey{Type: job.typ, Key: job.key}
-		// Check if this key already exists, if so, ignore it
-		if _, exists := m.Items[key]; !exists {
-			// Only add metrics for new keys
-			m.Items[key] = MetricItem{
-				Bytes:  bytes,
-				Tokens: tokens,
-				Lines:  lines,
-			}
+		m.process(job)
+	}
+}
+
+// process counts the content of a job and marks it done
+func (m *OutputMetrics) process(job job) {
+	defer m.jobDone()
+
+	key := MetricKey{Type: job.typ, Key: job.key}
+
+	// A key is counted once; later jobs only add their duration
+	if m.addDuration(key, job.duration) {
+		return
+	}
+
+	// Process the job
+	text := string(job.content)
+	bytes, tokens, lines := m.Ctr.Count(text)
+
+	// Update the metrics
+	m.mu.Lock()
+	// Another worker may have counted the same key in the meantime
+	if item, exists := m.Items[key]; exists {
+		item.Duration += job.duration
+		m.Items[key] = item
+	} else {
+		m.Items[key] = MetricItem{
+			Bytes:    bytes,
+			Tokens:   tokens,
+			Lines:    lines,
+			Duration: job.duration,
 		}
-		m.mu.Unlock()
+		m.addTotalLocked(tokens)
+	}
+	m.mu.Unlock()
+}
+
+// jobDone marks one pending job as counted
+func (m *OutputMetrics) jobDone() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending--
+	if m.pending == 0 {
+		m.idle.Broadcast()
+	}
+}
+
+// addDuration adds d to the item for key and reports whether it exists.
+func (m *OutputMetrics) addDuration(key MetricKey, d time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, exists := m.Items[key]
+	if exists {
+		item.Duration += d
+		m.Items[key] = item
 	}
+	return exists
 }
 
-// Add adds content to be processed for metrics
-func (m *OutputMetrics) Add(typ, key string, content []byte) {
-	m.jobs <- job{typ: typ, key: key, content: content}
+// Add adds content to be processed for metrics. The content of a key is
+// counted once, the first time it is added. An optional duration records how
+// long producing the item took, e.g. a template's render time; durations add
+// up over every Add of the key, so a partial rendered twice reports both.
+// Add after Wait is a no-op.
+func (m *OutputMetrics) Add(typ, key string, content []byte, d ...time.Duration) {
+	j := job{typ: typ, key: key, content: content}
+	for _, v := range d {
+		j.duration += v
+	}
+
+	m.sendMu.RLock()
+	defer m.sendMu.RUnlock()
+	if m.closed {
+		return
+	}
+	m.mu.Lock()
+	m.pending++
+	m.mu.Unlock()
+	m.jobs <- j
 }
 
 // AddBytesCountAsEstimate adds metrics using byte count with estimated token count
@@ -114,14 +202,107 @@ func (m *OutputMetrics) AddBytesCountAsEstimate(typ, key string, byteCount int)
 			Tokens: byteCount / 4,
 			Lines:  byteCount / 50,
 		}
+		m.addTotalLocked(byteCount / 4)
 	}
 }
 
-// Wait waits for all pending jobs to complete
-// It is idempotent and can be called multiple times safely
+// RecordSections makes AddSection keep the sections it is given. Without it
+// AddSection does nothing, so renders that do not need them pay no cost.
+func (m *OutputMetrics) RecordSections() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordSections = true
+}
+
+// RecordingSections reports whether RecordSections was called
+func (m *OutputMetrics) RecordingSections() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.recordSections
+}
+
+// AddSection appends a section of rendered output, if sections are recorded
+func (m *OutputMetrics) AddSection(typ, path, content string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.recordSections {
+		m.sections = append(m.sections, Section{Type: typ, Path: path, Content: content})
+	}
+}
+
+// Sections returns the recorded sections in the order they were added
+func (m *OutputMetrics) Sections() []Section {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.sections)
+}
+
+// SetBudget sets the maximum number of tokens the output may contain.
+// Once the running total exceeds it, OverBudget is signalled. 0 disables the budget.
+func (m *OutputMetrics) SetBudget(maxTokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budget = maxTokens
+	m.checkBudgetLocked()
+}
+
+// Budget returns the configured token budget (0 = unlimited)
+func (m *OutputMetrics) Budget() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.budget
+}
+
+// TotalTokens returns the running token total of all metrics recorded so far
+func (m *OutputMetrics) TotalTokens() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+// OverBudget returns a channel that receives a value the first time the
+// running token total exceeds the budget set with SetBudget
+func (m *OutputMetrics) OverBudget() <-chan struct{} {
+	return m.overBudget
+}
+
+// addTotalLocked adds tokens to the running total and checks the budget.
+// Caller **must** hold m.mu.
+func (m *OutputMetrics) addTotalLocked(tokens int) {
+	m.total += tokens
+	m.checkBudgetLocked()
+}
+
+// checkBudgetLocked signals OverBudget if the total exceeds the budget.
+// Caller **must** hold m.mu.
+func (m *OutputMetrics) checkBudgetLocked() {
+	if m.budget <= 0 || m.total <= m.budget || m.overBudget == nil {
+		return
+	}
+	m.overOnce.Do(func() {
+		m.overBudget <- struct{}{}
+	})
+}
+
+// Flush waits for the jobs added so far to be counted. Unlike Wait it keeps
+// the workers running, so Add can still be called afterwards.
+func (m *OutputMetrics) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.pending > 0 {
+		m.idle.Wait()
+	}
+}
+
+// Wait waits for all pending jobs to complete and stops the workers.
+// It is idempotent and can be called multiple times safely; Add after Wait
+// is a no-op.
 func (m *OutputMetrics) Wait() {
 	// Close the jobs channel exactly once, if it exists
 	m.closeOnce.Do(func() {
+		m.sendMu.Lock()
+		defer m.sendMu.Unlock()
+		m.closed = true
 		if m.jobs != nil {
 			close(m.jobs)
 		}
@@ -152,16 +333,69 @@ func (m *OutputMetrics) SumBy(typeName string) MetricItem {
 	return m.sumByLocked(typeName)
 }
 
-// MarshalJSON marshals the metrics to JSON with string keys
+// exportItem is a single metric as it appears in the JSON and CSV exports
+type exportItem struct {
+	Type   string `json:"type"`
+	Key    string `json:"key"`
+	Tokens int    `json:"tokens"`
+	Bytes  int    `json:"bytes"`
+}
+
+// exportItemsLocked returns all metrics sorted by type, then key.
+// Caller **must** hold m.mu.
+func (m *OutputMetrics) exportItemsLocked() []exportItem {
+	items := make([]exportItem, 0, len(m.Items))
+	for k, v := range m.Items {
+		items = append(items, exportItem{Type: k.Type, Key: k.Key, Tokens: v.Tokens, Bytes: v.Bytes})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Type != items[j].Type {
+			return items[i].Type < items[j].Type
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// MarshalJSON flushes pending jobs, then marshals the metrics as an
+// "items" array plus a "total_tokens" summary
 func (m *OutputMetrics) MarshalJSON() ([]byte, error) {
+	m.Flush()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Create a map with string keys
-	result := make(map[string]MetricItem, len(m.Items))
-	for k, v := range m.Items {
-		result[k.String()] = v
+	items := m.exportItemsLocked()
+	total := 0
+	for _, it := range items {
+		total += it.Tokens
 	}
 
-	return json.Marshal(result)
+	return json.Marshal(struct {
+		Items       []exportItem `json:"items"`
+		TotalTokens int          `json:"total_tokens"`
+	}{items, total})
+}
+
+// WriteCSV flushes pending jobs, then writes the metrics as CSV with a
+// header row followed by one row per item
+func (m *OutputMetrics) WriteCSV(w io.Writer) error {
+	m.Flush()
+
+	m.mu.Lock()
+	items := m.exportItemsLocked()
+	m.mu.Unlock()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"type", "key", "tokens", "bytes"}); err != nil {
+		return err
+	}
+	for _, it := range items {
+		row := []string{it.Type, it.Key, strconv.Itoa(it.Tokens), strconv.Itoa(it.Bytes)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
 }
@@ -1,7 +1,10 @@
 package metrics
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOutputMetrics(t *testing.T) {
@@ -42,6 +45,24 @@ func TestOutputMetrics(t *testing.T) {
 	}
 }
 
+func TestOutputMetricsDuration(t *testing.T) {
+	metrics := NewOutputMetrics(&SimpleCounter{}, 2)
+
+	metrics.Add("template", "a.md", []byte("body"))
+	metrics.Add("template", "a.md", []byte("body"), 2*time.Millisecond)
+	metrics.Add("template", "a.md", []byte("body"), 3*time.Millisecond)
+	metrics.Wait()
+
+	item := metrics.Items[NewKey("template", "a.md")]
+	if item.Duration != 5*time.Millisecond {
+		t.Errorf("Expected durations to add up to 5ms, got %v", item.Duration)
+	}
+	// the content is counted once
+	if metrics.TotalTokens() != item.Tokens {
+		t.Errorf("Expected total %d to count a.md once, got %d", item.Tokens, metrics.TotalTokens())
+	}
+}
+
 func TestMetricKey(t *testing.T) {
 	// Test NewKey and String methods
 	key := NewKey("file", "path/to/file.go")
@@ -80,3 +101,103 @@ func TestSimpleCounter(t *testing.T) {
 		t.Errorf("Token count should be positive, got %d", tokens)
 	}
 }
+
+func TestOutputMetricsExport(t *testing.T) {
+	m := NewOutputMetrics(&SimpleCounter{}, 2)
+	m.AddBytesCountAsEstimate("file", "b.go", 40)
+	m.AddBytesCountAsEstimate("file", "a,1.go", 8)
+	m.AddBytesCountAsEstimate("template", "main.md", 12)
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	expected := `{"items":[` +
+		`{"type":"file","key":"a,1.go","tokens":2,"bytes":8},` +
+		`{"type":"file","key":"b.go","tokens":10,"bytes":40},` +
+		`{"type":"template","key":"main.md","tokens":3,"bytes":12}` +
+		`],"total_tokens":15}`
+	if string(b) != expected {
+		t.Errorf("MarshalJSON mismatch\nexpected: %s\ngot:      %s", expected, b)
+	}
+
+	var buf strings.Builder
+	if err := m.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	expectedCSV := "type,key,tokens,bytes\n" +
+		"file,\"a,1.go\",2,8\n" +
+		"file,b.go,10,40\n" +
+		"template,main.md,3,12\n"
+	if buf.String() != expectedCSV {
+		t.Errorf("WriteCSV mismatch\nexpected: %q\ngot:      %q", expectedCSV, buf.String())
+	}
+
+	// Exporting only flushes, so the metrics can still be added to
+	m.Add("template", "late.md", []byte("late"))
+	if _, err := json.Marshal(m); err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	if _, ok := m.Items[NewKey("template", "late.md")]; !ok {
+		t.Errorf("Expected late.md to be counted by the second export")
+	}
+
+	// Wait is idempotent, and Add after it is a no-op
+	m.Wait()
+	m.Wait()
+	m.Add("template", "after.md", []byte("after"))
+	if _, ok := m.Items[NewKey("template", "after.md")]; ok {
+		t.Errorf("Expected Add after Wait to be ignored")
+	}
+}
+
+func TestOutputMetricsBudget(t *testing.T) {
+	m := NewOutputMetrics(&SimpleCounter{}, 1)
+	m.SetBudget(10)
+
+	m.AddBytesCountAsEstimate("file", "a.go", 40)
+	select {
+	case <-m.OverBudget():
+		t.Fatal("OverBudget signalled at exactly the budget")
+	default:
+	}
+
+	m.Add("template", "main.md", []byte("one more token"))
+	m.Wait()
+	select {
+	case <-m.OverBudget():
+	default:
+		t.Fatal("expected OverBudget to be signalled")
+	}
+
+	if m.TotalTokens() <= m.Budget() {
+		t.Errorf("expected total %d to exceed budget %d", m.TotalTokens(), m.Budget())
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	m := NewOutputMetrics(&SimpleCounter{}, 1)
+	m.AddBytesCountAsEstimate("file", "a.go", 4_000_000)
+
+	table := PriceTable{"test-model": {InputCostPer1M: 2, OutputCostPer1M: 8}}
+	est, err := EstimateCost(m, "test-model", table)
+	if err != nil {
+		t.Fatalf("EstimateCost failed: %v", err)
+	}
+	if est.InputTokens != 1_000_000 || est.OutputTokens != 0 {
+		t.Errorf("unexpected token counts: %+v", est)
+	}
+	if est.EstimatedUSD != 2 {
+		t.Errorf("expected $2, got $%v", est.EstimatedUSD)
+	}
+
+	if _, err := EstimateCost(m, "unknown", table); err == nil {
+		t.Error("expected error for unknown model")
+	}
+
+	for _, model := range DefaultPriceTables().Models() {
+		if _, err := EstimateCost(m, model, DefaultPriceTables()); err != nil {
+			t.Errorf("default table model %s: %v", model, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package selection
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExactPathMatcher matches one path exactly, e.g. "=cmd/vibe/main.go".
+// A Path ending in "/" ("=cmd/vibe/") matches the files directly inside that
+// directory, without descending into subdirectories.
+type ExactPathMatcher struct {
+	Path string
+}
+
+// NewExactPathMatcher parses an "=path" pattern. A leading "./" is ignored.
+func NewExactPathMatcher(pattern string) (ExactPathMatcher, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(pattern, "=")), "./")
+	if p == "" {
+		return ExactPathMatcher{}, fmt.Errorf("exact path pattern %q is empty", pattern)
+	}
+	return ExactPathMatcher{Path: p}, nil
+}
+
+// Match implements the Matcher interface for ExactPathMatcher
+func (m ExactPathMatcher) Match(paths []string) ([]string, error) {
+	var matched []string
+	for _, p := range paths {
+		if m.matches(strings.TrimPrefix(p, "./")) {
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
+
+func (m ExactPathMatcher) matches(p string) bool {
+	dir, isDir := strings.CutSuffix(m.Path, "/")
+	if !isDir {
+		return p == m.Path
+	}
+	rest, ok := strings.CutPrefix(p, dir+"/")
+	return ok && rest != "" && !strings.Contains(rest, "/")
+}
@@ -23,11 +23,28 @@ type FileSelectionContent struct {
 	Range   *LineRange // Line range, nil means the whole file content
 }
 
+// ColRange represents a line:column span in a file. Lines and columns are 1-based.
+type ColRange struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+}
+
+// String formats the range as "startLine:startCol-endLine:endCol"
+func (r ColRange) String() string {
+	return fmt.Sprintf("%d:%d-%d:%d", r.StartLine, r.StartCol, r.EndLine, r.EndCol)
+}
+
 // The pattern matches: <filepath>#<start>,<end> where start and end are integers
 var reFileSelection = regexp.MustCompile(`^(.+)#(\d+),(\d+)$`)
 
+// The pattern matches: <filepath>#<line>:<col>,<line>:<col>
+var reFileColSelection = regexp.MustCompile(`^(.+)#(\d+):(\d+),(\d+):(\d+)$`)
+
 // ParseFileSelection parses a path string that may contain a line range specification
-// Format: path#start,end where start and end are line numbers
+// Format: path#start,end where start and end are line numbers, or
+// path#L1:C1,L2:C2 to select a span with column precision
 // Returns a FileSelection with the path and any line ranges found
 func ParseFileSelection(fsys fs.FS, path string) (FileSelection, error) {
 	if fsys == nil {
@@ -38,12 +55,16 @@ func ParseFileSelection(fsys fs.FS, path string) (FileSelection, error) {
 		return NewFileSelection(fsys, path, nil), nil
 	}
 
+	if matches := reFileColSelection.FindStringSubmatch(path); matches != nil {
+		return parseColSelection(fsys, matches)
+	}
+
 	// Use a regular expression to validate and parse the path format
 	matches := reFileSelection.FindStringSubmatch(path)
 
 	// If the pattern doesn't match, return an error
 	if matches == nil {
-		return FileSelection{}, fmt.Errorf("invalid file path format: must be in format path#start,end")
+		return FileSelection{}, fmt.Errorf("invalid file path format: must be in format path#start,end or path#line:col,line:col")
 	}
 
 	// Extract the file path and line numbers from the regex matches
@@ -65,11 +86,35 @@ func ParseFileSelection(fsys fs.FS, path string) (FileSelection, error) {
 	}}), nil
 }
 
+// parseColSelection builds a FileSelection from reFileColSelection matches.
+// The lines spanned by the column range are selected as a regular line range,
+// and the column range itself is kept for annotation.
+func parseColSelection(fsys fs.FS, matches []string) (FileSelection, error) {
+	var nums [4]int
+	for i := range nums {
+		n, err := strconv.Atoi(matches[i+2])
+		if err != nil {
+			return FileSelection{}, fmt.Errorf("invalid number in column range: %v", err)
+		}
+		nums[i] = n
+	}
+
+	cr := ColRange{StartLine: nums[0], StartCol: nums[1], EndLine: nums[2], EndCol: nums[3]}
+	if cr.StartLine > cr.EndLine || (cr.StartLine == cr.EndLine && cr.StartCol > cr.EndCol) {
+		return FileSelection{}, fmt.Errorf("invalid column range %s: start is after end", cr)
+	}
+
+	sel := NewFileSelection(fsys, matches[1], []LineRange{{Start: cr.StartLine, End: cr.EndLine}})
+	sel.ColRanges = []ColRange{cr}
+	return sel, nil
+}
+
 // FileSelection represents a file and its selected line ranges
 type FileSelection struct {
-	Path   string      // File path
-	Ranges []LineRange // Line ranges to include, empty means all lines
-	FS     fs.FS       // File system to read from (required)
+	Path      string      // File path
+	Ranges    []LineRange // Line ranges to include, empty means all lines
+	ColRanges []ColRange  // Optional column-precise spans, annotated when reading
+	FS        fs.FS       // File system to read from (required)
 }
 
 // NewFileSelection creates a new FileSelection with the given filesystem, path and ranges.
@@ -90,6 +135,18 @@ func NewFileSelection(fsys fs.FS, path string, ranges []LineRange) FileSelection
 // This method provides better performance than ReadString for large files.
 // Returns the number of bytes written (excluding header comments).
 func (fs *FileSelection) Read(w io.Writer) (int64, error) {
+	return fs.read(w, "", false)
+}
+
+// ReadFenced is like Read, but wraps each block of content in a Markdown code
+// fence tagged with lang. The fence is made longer than any backtick run in
+// the content, so the whole file is read into memory before writing.
+// Returns the number of content bytes written (excluding headers and fences).
+func (fs *FileSelection) ReadFenced(w io.Writer, lang string) (int64, error) {
+	return fs.read(w, lang, true)
+}
+
+func (fs *FileSelection) read(w io.Writer, lang string, fenced bool) (int64, error) {
 	var totalBytes int64
 
 	// Check if it's a lock file first (early return)
@@ -131,6 +188,14 @@ func (fs *FileSelection) Read(w io.Writer) (int64, error) {
 		// Write header comment
 		fmt.Fprintf(w, "\n<!-- Read File: %s -->\n", fs.Path)
 
+		if fenced {
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return 0, fmt.Errorf("failed to read file %s: %w", fs.Path, err)
+			}
+			return writeFenced(w, string(content), lang)
+		}
+
 		// Stream the entire file
 		bytesWritten, err := io.Copy(w, file)
 		return bytesWritten, err
@@ -147,10 +212,22 @@ func (fs *FileSelection) Read(w io.Writer) (int64, error) {
 			fmt.Fprintf(w, "\n<!-- Read File: %s -->\n", content.Path)
 		} else {
 			fmt.Fprintf(w, "\n<!-- Read File: %s#%d,%d -->\n", content.Path, content.Range.Start, content.Range.End)
+			for _, cr := range fs.ColRanges {
+				if cr.StartLine >= content.Range.Start && cr.EndLine <= content.Range.End {
+					fmt.Fprintf(w, "<!-- lines %s -->\n", cr)
+				}
+			}
 		}
 
-		n, err := io.WriteString(w, content.Content)
-		totalBytes += int64(n)
+		var n int64
+		if fenced {
+			n, err = writeFenced(w, content.Content, lang)
+		} else {
+			var wn int
+			wn, err = io.WriteString(w, content.Content)
+			n = int64(wn)
+		}
+		totalBytes += n
 		if err != nil {
 			return totalBytes, err
 		}
@@ -159,6 +236,40 @@ func (fs *FileSelection) Read(w io.Writer) (int64, error) {
 	return totalBytes, nil
 }
 
+// writeFenced writes content inside a code fence tagged with lang and
+// returns the number of content bytes written.
+func writeFenced(w io.Writer, content, lang string) (int64, error) {
+	fence := codeFence(content)
+	if _, err := fmt.Fprintf(w, "%s%s\n", fence, lang); err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, content)
+	if err != nil {
+		return int64(n), err
+	}
+	closing := fence + "\n"
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		closing = "\n" + closing
+	}
+	_, err = io.WriteString(w, closing)
+	return int64(n), err
+}
+
+// codeFence returns a backtick fence longer than the longest backtick run in
+// content, and at least three backticks long.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(content); i++ {
+		if content[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
 // ReadString reads selected line ranges from the file.
 // If Ranges is empty, it returns the entire file content.
 func (fs *FileSelection) ReadString() (string, error) {
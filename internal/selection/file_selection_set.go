@@ -1,6 +1,7 @@
 package selection
 
 import (
+	"slices"
 	"sort"
 )
 
@@ -33,12 +34,19 @@ func (s *FileSelectionSet) Add(selection FileSelection) {
 			// Coalesce the ranges
 			existing.Ranges = coalesceRanges(existing.Ranges)
 		}
+		// Keep every column span, dropping duplicates
+		for _, cr := range selection.ColRanges {
+			if !slices.Contains(existing.ColRanges, cr) {
+				existing.ColRanges = append(existing.ColRanges, cr)
+			}
+		}
 	} else {
 		// Create a copy of the selection to avoid modifying the original
 		newSelection := FileSelection{
-			Path:   selection.Path,
-			Ranges: selection.Ranges,
-			FS:     selection.FS,
+			Path:      selection.Path,
+			Ranges:    selection.Ranges,
+			ColRanges: selection.ColRanges,
+			FS:        selection.FS,
 		}
 
 		// If there are ranges, coalesce them
@@ -101,3 +109,56 @@ func (s *FileSelectionSet) Values() []FileSelection {
 
 	return result
 }
+
+// Union returns a new set containing every path in s or other. Ranges for a
+// path present in both are merged the same way Add merges them.
+func (s *FileSelectionSet) Union(other *FileSelectionSet) *FileSelectionSet {
+	result := NewFileSelectionSet()
+	for _, sel := range s.items {
+		result.Add(cloneSelection(sel))
+	}
+	for _, sel := range other.items {
+		result.Add(cloneSelection(sel))
+	}
+	return result
+}
+
+// Intersect returns a new set containing only the paths present in both s and
+// other. The ranges of the two selections are merged, so a whole-file
+// selection on either side keeps the whole file.
+func (s *FileSelectionSet) Intersect(other *FileSelectionSet) *FileSelectionSet {
+	result := NewFileSelectionSet()
+	for path, sel := range s.items {
+		theirs, ok := other.items[path]
+		if !ok {
+			continue
+		}
+		result.Add(cloneSelection(sel))
+		result.Add(cloneSelection(theirs))
+	}
+	return result
+}
+
+// Difference returns a new set containing the paths in s that are not in
+// other, with their original ranges.
+func (s *FileSelectionSet) Difference(other *FileSelectionSet) *FileSelectionSet {
+	result := NewFileSelectionSet()
+	for path, sel := range s.items {
+		if _, ok := other.items[path]; ok {
+			continue
+		}
+		result.Add(cloneSelection(sel))
+	}
+	return result
+}
+
+// cloneSelection copies sel so that coalescing in the new set never touches
+// the ranges slice owned by the source set.
+func cloneSelection(sel *FileSelection) FileSelection {
+	return FileSelection{
+		Path:      sel.Path,
+		Ranges:    slices.Clone(sel.Ranges),
+		ColRanges: slices.Clone(sel.ColRanges),
+		FS:        sel.FS,
+	}
+}
@@ -0,0 +1,96 @@
+package selection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSet(selections ...FileSelection) *FileSelectionSet {
+	set := NewFileSelectionSet()
+	set.AddAll(selections)
+	return set
+}
+
+func TestFileSelectionSetOperations(t *testing.T) {
+	left := func() *FileSelectionSet {
+		return newTestSet(
+			FileSelection{Path: "a.go", Ranges: []LineRange{{Start: 1, End: 5}}},
+			FileSelection{Path: "b.go", Ranges: []LineRange{{Start: 10, End: 20}}},
+			FileSelection{Path: "c.go"},
+			FileSelection{Path: "d.go", Ranges: []LineRange{{Start: 3, End: 4}}},
+		)
+	}
+	right := func() *FileSelectionSet {
+		return newTestSet(
+			FileSelection{Path: "a.go", Ranges: []LineRange{{Start: 4, End: 8}}},
+			FileSelection{Path: "b.go"},
+			FileSelection{Path: "c.go", Ranges: []LineRange{{Start: 1, End: 2}}},
+			FileSelection{Path: "e.go", Ranges: []LineRange{{Start: 7, End: 9}}},
+		)
+	}
+
+	t.Run("union", func(t *testing.T) {
+		assert := assert.New(t)
+		got := left().Union(right())
+		assert.Equal([]FileSelection{
+			{Path: "a.go", Ranges: []LineRange{{Start: 1, End: 8}}},
+			{Path: "b.go"},
+			{Path: "c.go"},
+			{Path: "d.go", Ranges: []LineRange{{Start: 3, End: 4}}},
+			{Path: "e.go", Ranges: []LineRange{{Start: 7, End: 9}}},
+		}, got.Values())
+	})
+
+	t.Run("intersect", func(t *testing.T) {
+		assert := assert.New(t)
+		got := left().Intersect(right())
+		assert.Equal([]FileSelection{
+			{Path: "a.go", Ranges: []LineRange{{Start: 1, End: 8}}},
+			{Path: "b.go"},
+			{Path: "c.go"},
+		}, got.Values())
+	})
+
+	t.Run("difference", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal([]FileSelection{
+			{Path: "d.go", Ranges: []LineRange{{Start: 3, End: 4}}},
+		}, left().Difference(right()).Values())
+		assert.Equal([]FileSelection{
+			{Path: "e.go", Ranges: []LineRange{{Start: 7, End: 9}}},
+		}, right().Difference(left()).Values())
+	})
+
+	t.Run("operands are not modified", func(t *testing.T) {
+		assert := assert.New(t)
+		l, r := left(), right()
+		l.Union(r)
+		l.Intersect(r)
+		l.Difference(r)
+		assert.Equal(left().Values(), l.Values())
+		assert.Equal(right().Values(), r.Values())
+	})
+
+	t.Run("column ranges are merged", func(t *testing.T) {
+		assert := assert.New(t)
+		first := ColRange{StartLine: 2, StartCol: 3, EndLine: 2, EndCol: 9}
+		second := ColRange{StartLine: 5, StartCol: 1, EndLine: 6, EndCol: 4}
+		l := newTestSet(FileSelection{Path: "a.go", Ranges: []LineRange{{Start: 2, End: 2}}, ColRanges: []ColRange{first}})
+		r := newTestSet(FileSelection{Path: "a.go", Ranges: []LineRange{{Start: 5, End: 6}}, ColRanges: []ColRange{first, second}})
+		want := []FileSelection{
+			{Path: "a.go", Ranges: []LineRange{{Start: 2, End: 2}, {Start: 5, End: 6}}, ColRanges: []ColRange{first, second}},
+		}
+		assert.Equal(want, l.Union(r).Values())
+		assert.Equal(want, l.Intersect(r).Values())
+	})
+
+	t.Run("empty sets", func(t *testing.T) {
+		assert := assert.New(t)
+		empty := NewFileSelectionSet()
+		assert.Equal(4, left().Union(empty).Len())
+		assert.Equal(0, left().Intersect(empty).Len())
+		assert.Equal(4, left().Difference(empty).Len())
+		assert.Equal(0, empty.Difference(left()).Len())
+	})
+}
@@ -168,3 +168,52 @@ func TestParseLineRangeFromPath(t *testing.T) {
 		assert.Contains(err.Error(), "invalid file path format")
 	})
 }
+
+func TestParseColRangeFromPath(t *testing.T) {
+	assert := assert.New(t)
+	fsys := os.DirFS(".")
+
+	t.Run("PathWithColRange", func(t *testing.T) {
+		result, err := ParseFileSelection(fsys, "path/to/file.go#5:3,8:10")
+		assert.NoError(err)
+		assert.Equal("path/to/file.go", result.Path)
+		assert.Equal([]LineRange{{Start: 5, End: 8}}, result.Ranges)
+		assert.Equal([]ColRange{{StartLine: 5, StartCol: 3, EndLine: 8, EndCol: 10}}, result.ColRanges)
+	})
+
+	t.Run("LineRangeHasNoColRanges", func(t *testing.T) {
+		result, err := ParseFileSelection(fsys, "path/to/file.go#10,20")
+		assert.NoError(err)
+		assert.Nil(result.ColRanges)
+	})
+
+	t.Run("ColRangeStartAfterEnd", func(t *testing.T) {
+		_, err := ParseFileSelection(fsys, "path/to/file.go#5:9,5:2")
+		assert.Error(err)
+		assert.Contains(err.Error(), "start is after end")
+	})
+
+	t.Run("PartialColRange", func(t *testing.T) {
+		_, err := ParseFileSelection(fsys, "path/to/file.go#5:3,8")
+		assert.Error(err)
+		assert.Contains(err.Error(), "invalid file path format")
+	})
+}
+
+func TestFileSelectionReadColRange(t *testing.T) {
+	assert := assert.New(t)
+
+	tempDir := t.TempDir()
+	var content strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&content, "Line %d\n", i)
+	}
+	assert.NoError(os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte(content.String()), 0644))
+
+	sel, err := ParseFileSelection(os.DirFS(tempDir), "test.txt#2:3,3:4")
+	assert.NoError(err)
+
+	out, err := sel.ReadString()
+	assert.NoError(err)
+	assert.Equal("\n<!-- Read File: test.txt#2,3 -->\n<!-- lines 2:3-3:4 -->\nLine 2\nLine 3\n", out)
+}
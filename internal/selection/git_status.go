@@ -0,0 +1,107 @@
+package selection
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	setpkg "github.com/hayeah/fork2/internal/set"
+)
+
+// GitStatusMatcher matches paths by their state in `git status`:
+//
+//   - "git:modified"  – changed in the index or the working tree
+//   - "git:staged"    – any change staged in the index
+//   - "git:untracked" – not tracked by git
+//
+// Paths are relative to Dir (the current directory if empty), which must be
+// inside a git repository.
+type GitStatusMatcher struct {
+	Status string
+	Dir    string
+}
+
+var gitStatuses = map[string]func(x, y byte) bool{
+	"modified":  func(x, y byte) bool { return x == 'M' || y == 'M' },
+	"staged":    func(x, y byte) bool { return x != ' ' && x != '?' && x != '!' },
+	"untracked": func(x, y byte) bool { return x == '?' && y == '?' },
+}
+
+// NewGitStatusMatcher parses a "git:<status>" pattern.
+func NewGitStatusMatcher(pattern string) (GitStatusMatcher, error) {
+	status := strings.TrimPrefix(pattern, "git:")
+	if _, ok := gitStatuses[status]; !ok {
+		return GitStatusMatcher{}, fmt.Errorf("unknown git status %q, expected git:modified, git:staged or git:untracked", status)
+	}
+	return GitStatusMatcher{Status: status}, nil
+}
+
+// Match implements the Matcher interface for GitStatusMatcher
+func (m GitStatusMatcher) Match(paths []string) ([]string, error) {
+	changed, err := m.statusPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, p := range paths {
+		if changed.Contains(p) {
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
+
+// statusPaths runs `git status` and returns the paths, relative to m.Dir,
+// whose status code matches m.Status.
+func (m GitStatusMatcher) statusPaths() (*setpkg.Set[string], error) {
+	prefix, err := m.git("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	out, err := m.git("status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	want := gitStatuses[m.Status]
+	result := setpkg.NewSet[string]()
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		x, y, path := e[0], e[1], e[3:]
+		if x == 'R' || x == 'C' {
+			i++ // the next entry is the original path
+		}
+		if !want(x, y) {
+			continue
+		}
+		// git reports paths relative to the repo root
+		if rel, ok := strings.CutPrefix(path, prefix); ok {
+			result.Add(rel)
+		}
+	}
+	return result, nil
+}
+
+func (m GitStatusMatcher) git(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = m.Dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git status failed (not a git repository?): %s", msg)
+	}
+	return string(out), nil
+}
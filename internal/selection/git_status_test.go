@@ -0,0 +1,93 @@
+package selection_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	selectionPkg "github.com/hayeah/fork2/internal/selection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitStatusMatcher(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, contents string) {
+		t.Helper()
+		fp := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fp), 0o755))
+		assert.NoError(t, os.WriteFile(fp, []byte(contents), 0o644))
+	}
+
+	git("init", "-q")
+	for _, f := range []string{"clean.go", "edited.go", "staged.go", "sub/both.go", "old.go"} {
+		write(f, "v1")
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	write("edited.go", "v2")
+	write("staged.go", "v2")
+	write("sub/both.go", "v2")
+	git("add", "staged.go", "sub/both.go")
+	write("sub/both.go", "v3")
+	git("mv", "old.go", "renamed.go")
+	write("new file.go", "new")
+	write("sub/new.go", "new")
+
+	all := []string{"clean.go", "edited.go", "staged.go", "sub/both.go", "renamed.go", "new file.go", "sub/new.go"}
+
+	match := func(pattern string, paths []string) []string {
+		t.Helper()
+		m, err := selectionPkg.ParseMatcher(pattern)
+		assert.NoError(t, err)
+		got, err := m.Match(paths)
+		assert.NoError(t, err)
+		return got
+	}
+
+	t.Chdir(dir)
+	eq(t, match("git:modified", all), []string{"edited.go", "staged.go", "sub/both.go"})
+	eq(t, match("git:staged", all), []string{"staged.go", "sub/both.go", "renamed.go"})
+	eq(t, match("git:untracked", all), []string{"new file.go", "sub/new.go"})
+	eq(t, match("git:modified | sub", all), []string{"sub/both.go"})
+
+	// paths are relative to the current directory
+	t.Chdir(filepath.Join(dir, "sub"))
+	eq(t, match("git:untracked", []string{"both.go", "new.go"}), []string{"new.go"})
+
+	_, err := selectionPkg.ParseMatcher("git:bogus")
+	assert.Error(t, err)
+
+	// outside a repository
+	t.Chdir(t.TempDir())
+
+	// RootMatchers runs git in the tree root rather than the current directory
+	matchers, err := selectionPkg.ParseMatchersFromString("git:modified | sub")
+	assert.NoError(t, err)
+	matchers = selectionPkg.RootMatchers(matchers, dir, os.DirFS(dir))
+	got, err := matchers[0].Match(all)
+	assert.NoError(t, err)
+	eq(t, got, []string{"sub/both.go"})
+
+	m, err := selectionPkg.ParseMatcher("git:modified")
+	assert.NoError(t, err)
+	_, err = m.Match(all)
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package selection
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// GlobMatcher matches paths against a shell-style glob.
+//
+//   - "*.go"           – a pattern without "/" matches the base name at any depth
+//   - "cmd/*.go"       – "*" and "?" never cross a "/"
+//   - "cmd/**"         – "**" matches any number of directories
+//   - "**/*_test.go"   – including none
+type GlobMatcher struct {
+	Pattern string
+}
+
+// isGlobPattern reports whether pattern should be matched as a glob rather
+// than fuzzily: it contains "*" or "?" and is a single term.
+func isGlobPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?") && !strings.ContainsAny(pattern, " \t")
+}
+
+// NewGlobMatcher creates a GlobMatcher, validating the pattern syntax.
+func NewGlobMatcher(pattern string) (GlobMatcher, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+	for _, seg := range strings.Split(pattern, "/") {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return GlobMatcher{}, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return GlobMatcher{Pattern: pattern}, nil
+}
+
+// Match implements the Matcher interface for GlobMatcher
+func (m GlobMatcher) Match(paths []string) ([]string, error) {
+	var matched []string
+	for _, p := range paths {
+		if m.matches(p) {
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
+
+func (m GlobMatcher) matches(p string) bool {
+	if !strings.Contains(m.Pattern, "/") {
+		ok, _ := path.Match(m.Pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(m.Pattern, "/"), strings.Split(p, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments.
+func matchSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
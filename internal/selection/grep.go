@@ -0,0 +1,88 @@
+package selection
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// GrepMatcher matches files whose content matches a regular expression, e.g.
+// "grep:NewRenderer" or "grep:func \w+Matcher". Since "|" and ";" combine
+// patterns, write alternation as a union: "grep:foo;grep:bar".
+//
+// Files are read from FS (the current directory if nil), NumCPU at a time.
+// Files that cannot be read never match.
+type GrepMatcher struct {
+	Regexp *regexp.Regexp
+	FS     fs.FS
+}
+
+// NewGrepMatcher parses a "grep:<regexp>" pattern.
+func NewGrepMatcher(pattern string) (GrepMatcher, error) {
+	expr := strings.TrimPrefix(pattern, "grep:")
+	if expr == "" {
+		return GrepMatcher{}, fmt.Errorf("grep pattern %q is empty", pattern)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return GrepMatcher{}, fmt.Errorf("invalid grep pattern %q: %w", pattern, err)
+	}
+	return GrepMatcher{Regexp: re}, nil
+}
+
+// Match implements the Matcher interface for GrepMatcher
+func (m GrepMatcher) Match(paths []string) ([]string, error) {
+	fsys := m.FS
+	if fsys == nil {
+		fsys = os.DirFS(".")
+	}
+
+	hits := make([]bool, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hits[i] = m.fileMatches(fsys, strings.TrimPrefix(paths[i], "./"))
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var matched []string
+	for i, p := range paths {
+		if hits[i] {
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
+
+// fileMatches scans name line by line and stops at the first match.
+func (m GrepMatcher) fileMatches(fsys fs.FS, name string) bool {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if m.Regexp.Match(scanner.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
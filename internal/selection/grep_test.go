@@ -0,0 +1,49 @@
+package selection_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	selectionPkg "github.com/hayeah/fork2/internal/selection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrepMatcher(t *testing.T) {
+	fsys := fstest.MapFS{
+		"render.go":      {Data: []byte("package render\n\nfunc NewRenderer() {}\n")},
+		"cmd/main.go":    {Data: []byte("package main\n\n// uses render.NewRenderer\n")},
+		"cmd/other.go":   {Data: []byte("package main\n")},
+		"docs/README.md": {Data: []byte("# Docs\n\nCall NewRenderers everywhere.\n")},
+	}
+	paths := []string{"render.go", "./cmd/main.go", "cmd/other.go", "docs/README.md", "missing.go"}
+
+	m, err := selectionPkg.ParseMatcher(`grep:\bNewRenderer\(`)
+	assert.NoError(t, err)
+	gm, ok := m.(selectionPkg.GrepMatcher)
+	assert.True(t, ok)
+	gm.FS = fsys
+
+	matched, err := gm.Match(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"render.go"}, matched)
+
+	gm, err = selectionPkg.NewGrepMatcher("grep:NewRenderer")
+	assert.NoError(t, err)
+	gm.FS = fsys
+	matched, err = gm.Match(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"render.go", "./cmd/main.go", "docs/README.md"}, matched)
+
+	// RootMatchers reaches grep: inside compound and negated patterns
+	matchers, err := selectionPkg.ParseMatchersFromString(".go | grep:NewRenderer\n!grep:render\\.NewRenderer")
+	assert.NoError(t, err)
+	matchers = selectionPkg.RootMatchers(matchers, "", fsys)
+	matched, err = matchers[0].Match(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"render.go"}, matched)
+
+	for _, bad := range []string{"grep:", "grep:(unclosed"} {
+		_, err := selectionPkg.ParseMatcher(bad)
+		assert.Error(t, err, bad)
+	}
+}
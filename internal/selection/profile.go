@@ -0,0 +1,83 @@
+package selection
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Profile is a named select pattern kept in a ProfileStore.
+type Profile struct {
+	Select string `toml:"select"`
+}
+
+// ProfileStore keeps named select patterns in a TOML file, e.g.
+//
+//	[profiles.go-src]
+//	select = "*.go\n!*_test.go"
+type ProfileStore struct {
+	Path string
+}
+
+// profileFile is the on-disk layout of a ProfileStore.
+type profileFile struct {
+	Profiles map[string]Profile `toml:"profiles"`
+}
+
+// DefaultProfileStore returns the store at ~/.vibe/profiles.toml.
+func DefaultProfileStore() (ProfileStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ProfileStore{}, err
+	}
+	return ProfileStore{Path: filepath.Join(home, ".vibe", "profiles.toml")}, nil
+}
+
+// Load returns every profile in the store. A missing file holds no profiles.
+func (s ProfileStore) Load() (map[string]Profile, error) {
+	var f profileFile
+	if _, err := toml.DecodeFile(s.Path, &f); err != nil {
+		if os.IsNotExist(err) {
+			return map[string]Profile{}, nil
+		}
+		return nil, fmt.Errorf("failed to parse profiles %s: %w", s.Path, err)
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]Profile{}
+	}
+	return f.Profiles, nil
+}
+
+// Get returns the named profile, and whether the store has it.
+func (s ProfileStore) Get(name string) (Profile, bool, error) {
+	profiles, err := s.Load()
+	if err != nil {
+		return Profile{}, false, err
+	}
+	p, ok := profiles[name]
+	return p, ok, nil
+}
+
+// Save adds or replaces the named profile, creating the file if needed.
+func (s ProfileStore) Save(name string, p Profile) error {
+	if name == "" {
+		return fmt.Errorf("profile name is empty")
+	}
+	profiles, err := s.Load()
+	if err != nil {
+		return err
+	}
+	profiles[name] = p
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(profileFile{Profiles: profiles}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,45 @@
+package selection_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	selectionPkg "github.com/hayeah/fork2/internal/selection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".vibe", "profiles.toml")
+	store := selectionPkg.ProfileStore{Path: path}
+
+	// A missing file holds no profiles
+	profiles, err := store.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	assert.NoError(t, store.Save("go-src", selectionPkg.Profile{Select: "*.go\n!*_test.go"}))
+	assert.NoError(t, store.Save("docs", selectionPkg.Profile{Select: "*.md"}))
+
+	p, ok, err := store.Get("go-src")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "*.go\n!*_test.go", p.Select)
+
+	_, ok, err = store.Get("missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Saving again replaces the profile and keeps the others
+	assert.NoError(t, store.Save("go-src", selectionPkg.Profile{Select: "cmd/"}))
+	profiles, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]selectionPkg.Profile{
+		"go-src": {Select: "cmd/"},
+		"docs":   {Select: "*.md"},
+	}, profiles)
+
+	assert.NoError(t, os.WriteFile(path, []byte("[profiles\n"), 0644))
+	_, err = store.Load()
+	assert.ErrorContains(t, err, "failed to parse profiles")
+}
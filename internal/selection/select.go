@@ -6,6 +6,11 @@
 // 1. Fuzzy matching: "foo" matches any path containing "foo"
 // 2. Compound patterns: "foo|bar" matches paths containing both "foo" AND "bar"
 // 3. Union patterns: "foo;bar" matches paths containing either "foo" OR "bar"
+// 4. Glob patterns: "*.go", "cmd/**/*.go" (any single term containing * or ?)
+// 5. Git status: "git:modified", "git:staged", "git:untracked"
+// 6. File size: "size:<100KB", "size:>1MiB"
+// 7. Exact path: "=cmd/vibe/main.go", or "=cmd/vibe/" for the files directly in a directory
+// 8. File content: "grep:NewRenderer" matches files containing the regexp
 //
 // # Special Cases
 //
@@ -17,6 +22,7 @@ package selection
 import (
 	"bufio"
 	"fmt"
+	"io/fs"
 	"strings"
 
 	"github.com/hayeah/fork2/fzf"
@@ -98,6 +104,31 @@ func (m UnionMatcher) Match(paths []string) ([]string, error) {
 	return resultSet.Values(), nil
 }
 
+// NegationMatcher matches every path that Matcher does not match. On a line
+// of its own in ParseMatchersFromString it excludes paths from the combined
+// result of the other lines.
+type NegationMatcher struct {
+	Matcher Matcher
+}
+
+// Match implements the Matcher interface for NegationMatcher
+func (m NegationMatcher) Match(paths []string) ([]string, error) {
+	excluded, err := m.Matcher.Match(paths)
+	if err != nil {
+		return nil, err
+	}
+	excludedSet := setpkg.NewSet[string]()
+	excludedSet.AddValues(excluded)
+
+	var kept []string
+	for _, p := range paths {
+		if !excludedSet.Contains(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
 // splitMatchers splits a pattern by the given separator and parses each part into a Matcher
 func splitMatchers(pattern, separator string) ([]Matcher, error) {
 	parts := strings.Split(pattern, separator)
@@ -126,6 +157,18 @@ func ParseMatcher(pattern string) (Matcher, error) {
 		return nil, fmt.Errorf("patterns with '../' are not supported for security reasons")
 	}
 
+	// A single negated term, e.g. "!*_test.go"
+	if strings.HasPrefix(pattern, "!") && !strings.ContainsAny(pattern, " \t|;") {
+		if pattern == "!" {
+			return nil, fmt.Errorf("negation pattern is empty")
+		}
+		inner, err := ParseMatcher(pattern[1:])
+		if err != nil {
+			return nil, err
+		}
+		return NegationMatcher{Matcher: inner}, nil
+	}
+
 	// Check if this is a compound pattern with '|' operator (logical AND)
 	if strings.Contains(pattern, "|") {
 		subMatchers, err := splitMatchers(pattern, "|")
@@ -166,12 +209,42 @@ func ParseMatcher(pattern string) (Matcher, error) {
 		return UnionMatcher{Matchers: subMatchers}, nil
 	}
 
+	// git status filters, e.g. "git:modified"
+	if strings.HasPrefix(pattern, "git:") {
+		return NewGitStatusMatcher(pattern)
+	}
+
+	// exact paths, e.g. "=cmd/vibe/main.go"
+	if strings.HasPrefix(pattern, "=") {
+		return NewExactPathMatcher(pattern)
+	}
+
+	// content filters, e.g. "grep:func \w+Matcher"
+	if strings.HasPrefix(pattern, "grep:") {
+		return NewGrepMatcher(pattern)
+	}
+
+	// size filters, e.g. "size:<100KB"
+	if strings.HasPrefix(pattern, "size:") {
+		return NewSizeMatcher(pattern)
+	}
+
+	// Single-term patterns with wildcards are globs
+	if isGlobPattern(pattern) {
+		return NewGlobMatcher(pattern)
+	}
+
 	// Default to fuzzy matching
 	return NewFuzzyMatcher(pattern)
 }
 
 // ParseMatchersFromString parses a string containing multiple patterns into a slice of Matchers
 // It skips empty lines and comment lines that start with #
+//
+// Each line selects paths on its own (logical OR), except for lines that are a
+// single negated term such as "!*_test.go": these are subtracted from the
+// union of all other lines, or from all paths if there are no other lines.
+//
 // Example input:
 //
 //	cmd/.go
@@ -182,8 +255,12 @@ func ParseMatcher(pattern string) (Matcher, error) {
 //
 //	# exact path match and range
 //	=path/to/b.txt#1,5
+//
+//	# but no tests
+//	!*_test.go
 func ParseMatchersFromString(input string) ([]Matcher, error) {
 	var matchers []Matcher
+	var negations []Matcher
 	scanner := bufio.NewScanner(strings.NewReader(input))
 
 	for scanner.Scan() {
@@ -200,6 +277,10 @@ func ParseMatchersFromString(input string) ([]Matcher, error) {
 			return nil, fmt.Errorf("error parsing pattern '%s': %w", line, err)
 		}
 
+		if _, ok := matcher.(NegationMatcher); ok {
+			negations = append(negations, matcher)
+			continue
+		}
 		matchers = append(matchers, matcher)
 	}
 
@@ -207,5 +288,47 @@ func ParseMatchersFromString(input string) ([]Matcher, error) {
 		return nil, fmt.Errorf("error scanning input: %w", err)
 	}
 
-	return matchers, nil
+	if len(negations) == 0 {
+		return matchers, nil
+	}
+
+	// Subtract the negations from the union of the positive lines
+	var steps []Matcher
+	if len(matchers) > 0 {
+		steps = append(steps, UnionMatcher{Matchers: matchers})
+	}
+	steps = append(steps, negations...)
+	return []Matcher{CompoundMatcher{Matchers: steps}}, nil
+}
+
+// RootMatchers points the matchers that read files at the directory dir,
+// which fsys serves, instead of the current directory. Paths passed to Match
+// are then relative to dir. Wrapped matchers are rooted too.
+func RootMatchers(matchers []Matcher, dir string, fsys fs.FS) []Matcher {
+	rooted := make([]Matcher, len(matchers))
+	for i, m := range matchers {
+		rooted[i] = rootMatcher(m, dir, fsys)
+	}
+	return rooted
+}
+
+func rootMatcher(m Matcher, dir string, fsys fs.FS) Matcher {
+	switch m := m.(type) {
+	case CompoundMatcher:
+		return CompoundMatcher{Matchers: RootMatchers(m.Matchers, dir, fsys)}
+	case UnionMatcher:
+		return UnionMatcher{Matchers: RootMatchers(m.Matchers, dir, fsys)}
+	case NegationMatcher:
+		return NegationMatcher{Matcher: rootMatcher(m.Matcher, dir, fsys)}
+	case GrepMatcher:
+		m.FS = fsys
+		return m
+	case SizeMatcher:
+		m.Dir = dir
+		return m
+	case GitStatusMatcher:
+		m.Dir = dir
+		return m
+	}
+	return m
 }
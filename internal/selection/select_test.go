@@ -169,3 +169,170 @@ func TestCompoundMatcher(t *testing.T) {
 		})
 	}
 }
+
+// -----------------------------------------------------------------------------
+// GlobMatcher
+// -----------------------------------------------------------------------------
+
+func TestGlobMatcher(t *testing.T) {
+	globPaths := []string{
+		"main.go",
+		"main_test.go",
+		"cmd/vibe/main.go",
+		"cmd/vibe/out_test.go",
+		"cmd/tool.go",
+		"render/render.go",
+		"render/render_test.go",
+		"docs/guide.md",
+	}
+
+	cases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*.go", []string{"main.go", "main_test.go", "cmd/vibe/main.go", "cmd/vibe/out_test.go", "cmd/tool.go", "render/render.go", "render/render_test.go"}},
+		{"cmd/**", []string{"cmd/vibe/main.go", "cmd/vibe/out_test.go", "cmd/tool.go"}},
+		{"cmd/**/*.go", []string{"cmd/vibe/main.go", "cmd/vibe/out_test.go", "cmd/tool.go"}},
+		{"cmd/*.go", []string{"cmd/tool.go"}},
+		{"**/*_test.go", []string{"main_test.go", "cmd/vibe/out_test.go", "render/render_test.go"}},
+		{"./render/*_test.go", []string{"render/render_test.go"}},
+		{"docs/guide.?d", []string{"docs/guide.md"}},
+		{"*.md;cmd/*.go", []string{"docs/guide.md", "cmd/tool.go"}},
+		{"**/*.go | !test", []string{"main.go", "cmd/vibe/main.go", "cmd/tool.go", "render/render.go"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			m, err := selectionPkg.ParseMatcher(tc.pattern)
+			assert.NoError(t, err)
+			got, err := m.Match(globPaths)
+			assert.NoError(t, err)
+			eq(t, got, tc.want)
+		})
+	}
+
+	_, err := selectionPkg.ParseMatcher("cmd/[*.go")
+	assert.Error(t, err)
+
+	// multi-term patterns stay fuzzy
+	m, err := selectionPkg.ParseMatcher("cmd .go")
+	assert.NoError(t, err)
+	_, isGlob := m.(selectionPkg.GlobMatcher)
+	assert.False(t, isGlob)
+}
+
+// -----------------------------------------------------------------------------
+// Negation lines
+// -----------------------------------------------------------------------------
+
+// matchAll runs every matcher parsed from input and unions the results, the
+// same way DirectoryTree.SelectFiles does.
+func matchAll(t *testing.T, input string, paths []string) []string {
+	t.Helper()
+	matchers, err := selectionPkg.ParseMatchersFromString(input)
+	assert.NoError(t, err)
+
+	seen := map[string]bool{}
+	var got []string
+	for _, m := range matchers {
+		matched, err := m.Match(paths)
+		assert.NoError(t, err)
+		for _, p := range matched {
+			if !seen[p] {
+				seen[p] = true
+				got = append(got, p)
+			}
+		}
+	}
+	return got
+}
+
+func TestNegationPatterns(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			"glob minus tests",
+			"*.go\n!*_test.go",
+			[]string{"src/foo.go", "internal/baz.go"},
+		},
+		{
+			"negation applies after all positive lines",
+			"!*_test.go\n.go\n.md",
+			[]string{"src/foo.go", "internal/baz.go", "docs/bar.md", "README.md"},
+		},
+		{
+			"union line minus fuzzy negation",
+			".go;.md\n!internal",
+			[]string{"src/foo.go", "src/foo_test.go", "docs/bar.md", "README.md"},
+		},
+		{
+			"several negations",
+			"*\n!*.md\n!src",
+			[]string{"internal/baz_test.go", "internal/baz.go"},
+		},
+		{
+			"negation only selects everything else",
+			"!*.go",
+			[]string{"docs/bar.md", "README.md"},
+		},
+		{
+			"negated term inside a line stays a filter",
+			".go !_test.go",
+			[]string{"src/foo.go", "internal/baz.go"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eq(t, matchAll(t, tc.input, paths), tc.want)
+		})
+	}
+
+	_, err := selectionPkg.ParseMatcher("!")
+	assert.Error(t, err)
+}
+
+// -----------------------------------------------------------------------------
+// ExactPathMatcher (=path)
+// -----------------------------------------------------------------------------
+
+func TestExactPathMatcher(t *testing.T) {
+	paths := []string{
+		"cmd/vibe/main.go",
+		"cmd/vibe/main.go.bak",
+		"cmd/vibe/sub/main.go",
+		"cmd/main.go",
+		"./docs/intro.md",
+	}
+
+	cases := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"exact file", "=cmd/vibe/main.go", []string{"cmd/vibe/main.go"}},
+		{"dot slash prefix", "=./cmd/main.go", []string{"cmd/main.go"}},
+		{"candidate with dot slash", "=docs/intro.md", []string{"./docs/intro.md"}},
+		{"directory without recursion", "=cmd/vibe/", []string{"cmd/vibe/main.go", "cmd/vibe/main.go.bak"}},
+		{"no fuzzy fallback", "=main.go", nil},
+		{"union of exact paths", "=cmd/main.go;=cmd/vibe/sub/main.go", []string{"cmd/main.go", "cmd/vibe/sub/main.go"}},
+		{"negated", "=cmd/vibe/ | !=cmd/vibe/main.go.bak", []string{"cmd/vibe/main.go"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := selectionPkg.ParseMatcher(tc.pattern)
+			assert.NoError(t, err)
+			got, err := m.Match(paths)
+			assert.NoError(t, err)
+			eq(t, got, tc.want)
+		})
+	}
+
+	m, err := selectionPkg.ParseMatcher("=cmd/vibe/main.go")
+	assert.NoError(t, err)
+	assert.Equal(t, selectionPkg.ExactPathMatcher{Path: "cmd/vibe/main.go"}, m)
+
+	_, err = selectionPkg.ParseMatcher("=")
+	assert.Error(t, err)
+}
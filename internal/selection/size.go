@@ -0,0 +1,94 @@
+package selection
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SizeMatcher matches files by size, e.g. "size:<100KB", "size:>=1MiB" or
+// "size:500B" (exactly 500 bytes).
+//
+// Units are B, KB, MB and GB (powers of 1000) or KiB, MiB and GiB (powers of
+// 1024), case-insensitive. Paths are stat'ed relative to Dir (the current
+// directory if empty); paths that cannot be stat'ed never match.
+type SizeMatcher struct {
+	Op    string // one of "<", "<=", ">", ">=", "="
+	Bytes int64
+	Dir   string
+}
+
+var reSize = regexp.MustCompile(`^(<=|>=|<|>|=)?\s*(\d+(?:\.\d+)?)\s*([a-zA-Z]*)$`)
+
+var sizeUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+}
+
+// NewSizeMatcher parses a "size:<op><number><unit>" pattern.
+func NewSizeMatcher(pattern string) (SizeMatcher, error) {
+	spec := strings.TrimSpace(strings.TrimPrefix(pattern, "size:"))
+	m := reSize.FindStringSubmatch(spec)
+	if m == nil {
+		return SizeMatcher{}, fmt.Errorf("invalid size pattern %q, expected e.g. size:<100KB", pattern)
+	}
+
+	op := m[1]
+	if op == "" {
+		op = "="
+	}
+
+	n, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return SizeMatcher{}, fmt.Errorf("invalid size pattern %q: %w", pattern, err)
+	}
+
+	unit := strings.ToLower(m[3])
+	if unit == "" {
+		unit = "b"
+	}
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return SizeMatcher{}, fmt.Errorf("invalid size pattern %q: unknown unit %q (use B, KB, MB, GB, KiB, MiB or GiB)", pattern, m[3])
+	}
+
+	return SizeMatcher{Op: op, Bytes: int64(n * mult)}, nil
+}
+
+// Match implements the Matcher interface for SizeMatcher
+func (m SizeMatcher) Match(paths []string) ([]string, error) {
+	var matched []string
+	for _, p := range paths {
+		fi, err := os.Stat(filepath.Join(m.Dir, p))
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if m.compare(fi.Size()) {
+			matched = append(matched, p)
+		}
+	}
+	return matched, nil
+}
+
+func (m SizeMatcher) compare(size int64) bool {
+	switch m.Op {
+	case "<":
+		return size < m.Bytes
+	case "<=":
+		return size <= m.Bytes
+	case ">":
+		return size > m.Bytes
+	case ">=":
+		return size >= m.Bytes
+	default:
+		return size == m.Bytes
+	}
+}
@@ -0,0 +1,97 @@
+package selection_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	selectionPkg "github.com/hayeah/fork2/internal/selection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizeMatcher(t *testing.T) {
+	cases := []struct {
+		pattern string
+		op      string
+		bytes   int64
+	}{
+		{"size:<100KB", "<", 100_000},
+		{"size:>1MB", ">", 1_000_000},
+		{"size:500B", "=", 500},
+		{"size:500", "=", 500},
+		{"size:<=1KiB", "<=", 1024},
+		{"size:>=1.5mib", ">=", 1572864},
+		{"size:> 2GB", ">", 2_000_000_000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			m, err := selectionPkg.ParseMatcher(tc.pattern)
+			assert.NoError(t, err)
+			sm, ok := m.(selectionPkg.SizeMatcher)
+			assert.True(t, ok)
+			assert.Equal(t, tc.op, sm.Op)
+			assert.Equal(t, tc.bytes, sm.Bytes)
+		})
+	}
+
+	for _, bad := range []string{"size:", "size:<", "size:abc", "size:10XB", "size:<<10", "size:1.KB"} {
+		_, err := selectionPkg.ParseMatcher(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSizeMatcher(t *testing.T) {
+	dir := t.TempDir()
+	sizes := map[string]int{
+		"tiny.txt":      10,
+		"small.go":      999,
+		"kb.go":         1000,
+		"big/large.bin": 5000,
+	}
+	var paths []string
+	for name, n := range sizes {
+		fp := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fp), 0o755))
+		assert.NoError(t, os.WriteFile(fp, []byte(strings.Repeat("x", n)), 0o644))
+		paths = append(paths, name)
+	}
+	paths = append(paths, "missing.txt")
+
+	match := func(pattern string) []string {
+		t.Helper()
+		m, err := selectionPkg.NewSizeMatcher(pattern)
+		assert.NoError(t, err)
+		m.Dir = dir
+		got, err := m.Match(paths)
+		assert.NoError(t, err)
+		return got
+	}
+
+	eq(t, match("size:<1KB"), []string{"tiny.txt", "small.go"})
+	eq(t, match("size:<=1KB"), []string{"tiny.txt", "small.go", "kb.go"})
+	eq(t, match("size:>1KiB"), []string{"big/large.bin"})
+	eq(t, match("size:>=1000B"), []string{"kb.go", "big/large.bin"})
+	eq(t, match("size:10B"), []string{"tiny.txt"})
+
+	// relative to the current directory when used in a pattern
+	t.Chdir(dir)
+	m, err := selectionPkg.ParseMatcher(".go | size:<1KB")
+	assert.NoError(t, err)
+	got, err := m.Match(paths)
+	assert.NoError(t, err)
+	eq(t, got, []string{"small.go"})
+}
+
+func TestSizeMatcherRooted(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte(strings.Repeat("x", 2000)), 0o644))
+
+	matchers, err := selectionPkg.ParseMatchersFromString(".go | size:<1KB")
+	assert.NoError(t, err)
+	matchers = selectionPkg.RootMatchers(matchers, dir, os.DirFS(dir))
+	got, err := matchers[0].Match([]string{"a.go", "b.go"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, got)
+}
@@ -7,6 +7,7 @@
 package render
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -16,9 +17,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
 	"github.com/atotto/clipboard"
+	"golang.org/x/sync/errgroup"
 )
 
 /* -------------------------------------------------------------------------- */
@@ -148,6 +155,43 @@ func (l *HTTPLoader) Load(ctx context.Context) (string, error) {
 
 func httpFactory(arg string) (ContentLoader, error) { return &HTTPLoader{URL: arg}, nil }
 
+// ─── HTML → Markdown ─────────────────────────────────────────────────────────
+
+// HTMLLoader fetches a web page and converts it to Markdown. Only the <main>
+// or <article> element is kept if present, otherwise the whole <body>.
+type HTMLLoader struct{ URL string }
+
+func (l *HTMLLoader) Load(ctx context.Context) (string, error) {
+	page, err := (&HTTPLoader{URL: l.URL}).Load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML from %s: %w", l.URL, err)
+	}
+
+	sel := doc.Find("main").First()
+	if sel.Length() == 0 {
+		sel = doc.Find("article").First()
+	}
+	if sel.Length() == 0 {
+		sel = doc.Find("body")
+	}
+
+	conv := md.NewConverter(md.DomainFromURL(l.URL), true, nil)
+	return conv.Convert(sel), nil
+}
+
+func htmlFactory(arg string) (ContentLoader, error) {
+	u := strings.TrimPrefix(arg, "html:")
+	if u == "" {
+		return nil, fmt.Errorf("invalid html spec %q, expected html:URL", arg)
+	}
+	return &HTMLLoader{URL: u}, nil
+}
+
 // ─── Shell command ───────────────────────────────────────────────────────────
 
 type ShellLoader struct {
@@ -163,6 +207,30 @@ func (l *ShellLoader) Load(ctx context.Context) (string, error) {
 	return string(output), nil
 }
 
+// TransformLoader pipes the output of Inner through a shell command, e.g.
+// "jq .results", and returns what the command prints.
+type TransformLoader struct {
+	Inner   ContentLoader
+	Command string
+}
+
+func (l *TransformLoader) Load(ctx context.Context) (string, error) {
+	text, err := l.Inner.Load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", l.Command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("content transform %q failed: %w\nOutput:\n%s", l.Command, err, stderr.String())
+	}
+	return string(output), nil
+}
+
 func shellFactory(arg string) (ContentLoader, error) {
 	if i := strings.IndexRune(arg, ':'); i >= 0 {
 		arg = arg[i+1:]
@@ -170,6 +238,252 @@ func shellFactory(arg string) (ContentLoader, error) {
 	return &ShellLoader{Command: arg}, nil
 }
 
+// ─── Git blob ────────────────────────────────────────────────────────────────
+
+// GitBlobLoader loads a file as it was at a given git ref, e.g.
+// "git:HEAD~1:pkg/foo.go".
+type GitBlobLoader struct {
+	Ref  string
+	Path string
+	Dir  string // working directory for git; empty means the WithGitDir directory
+}
+
+func (l *GitBlobLoader) Load(ctx context.Context) (string, error) {
+	out, err := runGit(ctx, gitDir(ctx, l.Dir), "show", l.Ref+":"+l.Path)
+	if err != nil {
+		return "", fmt.Errorf("git: cannot read %s at %s: %w", l.Path, l.Ref, err)
+	}
+	return out, nil
+}
+
+type gitDirKey struct{}
+
+// WithGitDir returns a copy of ctx under which "git:" and "gitdiff:" content
+// sources run git in dir, e.g. the --root of the prompt, instead of the
+// current directory.
+func WithGitDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, gitDirKey{}, dir)
+}
+
+// gitDir returns dir, or the WithGitDir directory of ctx if dir is empty.
+func gitDir(ctx context.Context, dir string) string {
+	if dir != "" {
+		return dir
+	}
+	dir, _ = ctx.Value(gitDirKey{}).(string)
+	return dir
+}
+
+// runGit runs git in dir and returns its stdout. On failure the error
+// carries git's stderr message.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+func gitBlobFactory(arg string) (ContentLoader, error) {
+	spec := strings.TrimPrefix(arg, "git:")
+	ref, path, ok := strings.Cut(spec, ":")
+	if !ok || ref == "" || path == "" {
+		return nil, fmt.Errorf("invalid git spec %q, expected git:REF:path", arg)
+	}
+	return &GitBlobLoader{Ref: ref, Path: path}, nil
+}
+
+// ─── Git diff ────────────────────────────────────────────────────────────────
+
+// GitDiffLoader loads a unified diff from git. Rev is passed to git diff
+// as-is ("HEAD~1", "main..feature"); "staged" selects the index, and an
+// empty Rev the unstaged working tree changes.
+type GitDiffLoader struct {
+	Rev string
+	Dir string // working directory for git; empty means the WithGitDir directory
+}
+
+func (l *GitDiffLoader) Load(ctx context.Context) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("gitdiff: git not found: %w", err)
+	}
+	dir := gitDir(ctx, l.Dir)
+	if _, err := runGit(ctx, dir, "rev-parse", "--git-dir"); err != nil {
+		return "", fmt.Errorf("gitdiff: not a git repository: %w", err)
+	}
+
+	args := []string{"diff"}
+	switch l.Rev {
+	case "":
+	case "staged":
+		args = append(args, "--staged")
+	default:
+		args = append(args, l.Rev)
+	}
+
+	out, err := runGit(ctx, dir, args...)
+	if err != nil {
+		return "", fmt.Errorf("gitdiff: %w", err)
+	}
+	return out, nil
+}
+
+func gitDiffFactory(arg string) (ContentLoader, error) {
+	return &GitDiffLoader{Rev: strings.TrimPrefix(arg, "gitdiff:")}, nil
+}
+
+// ─── Rendered template ───────────────────────────────────────────────────────
+
+// TemplateLoader renders a template without its layout, like the "partial"
+// function, for "template:path". A loader made by the "template" scheme has no
+// Renderer of its own and uses the one attached to the load context with
+// WithTemplateRenderer.
+type TemplateLoader struct {
+	Renderer *Renderer
+	Data     Content
+	Path     string
+}
+
+type templateRendererKey struct{}
+
+type templateRenderer struct {
+	r    *Renderer
+	data Content
+}
+
+// WithTemplateRenderer returns a copy of ctx under which "template:" content
+// sources are rendered by r with data.
+func WithTemplateRenderer(ctx context.Context, r *Renderer, data Content) context.Context {
+	return context.WithValue(ctx, templateRendererKey{}, templateRenderer{r: r, data: data})
+}
+
+func (l *TemplateLoader) Load(ctx context.Context) (string, error) {
+	r, data := l.Renderer, l.Data
+	if r == nil {
+		tr, ok := ctx.Value(templateRendererKey{}).(templateRenderer)
+		if !ok {
+			return "", fmt.Errorf("template:%s: no renderer available to render it", l.Path)
+		}
+		r, data = tr.r, tr.data
+	}
+	if data == nil {
+		data = &AnyContent{}
+	}
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	// sources load in parallel, but a Renderer renders one template at a time.
+	// This is not a render of its own, so the includeOnce set is left as is.
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+	return r.renderPartial(ctx, l.Path, data)
+}
+
+func templateFactory(arg string) (ContentLoader, error) {
+	path := strings.TrimPrefix(arg, "template:")
+	if path == "" {
+		return nil, fmt.Errorf("invalid template spec %q, expected template:path", arg)
+	}
+	// the output depends on the data, so never serve it from the cache
+	return &NoCacheLoader{Inner: &TemplateLoader{Path: path}}, nil
+}
+
+// ─── Environment variable ────────────────────────────────────────────────────
+
+// EnvLoader loads the value of an environment variable. "env:NAME" fails if
+// NAME is unset; "env:NAME?" yields an empty string instead.
+type EnvLoader struct {
+	Name     string
+	Optional bool
+}
+
+func (l *EnvLoader) Load(ctx context.Context) (string, error) {
+	v, ok := os.LookupEnv(l.Name)
+	if !ok && !l.Optional {
+		return "", fmt.Errorf("environment variable %s is not set", l.Name)
+	}
+	return v, nil
+}
+
+func envFactory(arg string) (ContentLoader, error) {
+	name := strings.TrimPrefix(arg, "env:")
+	name, optional := strings.CutSuffix(name, "?")
+	if name == "" {
+		return nil, fmt.Errorf("invalid env spec %q, expected env:NAME or env:NAME?", arg)
+	}
+	return &EnvLoader{Name: name, Optional: optional}, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                  Caching                                   */
+/* -------------------------------------------------------------------------- */
+
+// CacheStore holds loaded content keyed by source spec, so that repeated
+// renders (e.g. in watch mode) can skip slow HTTP or shell sources.
+type CacheStore struct {
+	TTL     time.Duration
+	entries sync.Map // key → cacheEntry
+}
+
+type cacheEntry struct {
+	text string
+	at   time.Time
+}
+
+// NewCacheStore returns a CacheStore whose entries expire after ttl.
+func NewCacheStore(ttl time.Duration) *CacheStore {
+	return &CacheStore{TTL: ttl}
+}
+
+// CachedLoader wraps Inner, reusing its last successful result for TTL.
+type CachedLoader struct {
+	Inner ContentLoader
+	TTL   time.Duration
+
+	// Key identifies the value in Store, usually the content spec.
+	Key string
+	// Store holds cached values. If nil, they are kept in the loader itself.
+	Store *CacheStore
+
+	own CacheStore
+}
+
+func (l *CachedLoader) Load(ctx context.Context) (string, error) {
+	store := l.Store
+	if store == nil {
+		store = &l.own
+	}
+
+	if v, ok := store.entries.Load(l.Key); ok {
+		if e := v.(cacheEntry); time.Since(e.at) < l.TTL {
+			return e.text, nil
+		}
+	}
+
+	text, err := l.Inner.Load(ctx)
+	if err != nil {
+		return "", err
+	}
+	store.entries.Store(l.Key, cacheEntry{text: text, at: time.Now()})
+	return text, nil
+}
+
+// NoCacheLoader wraps Inner so that LoadContentSources never caches it.
+type NoCacheLoader struct{ Inner ContentLoader }
+
+func (l *NoCacheLoader) Load(ctx context.Context) (string, error) { return l.Inner.Load(ctx) }
+
 /* -------------------------------------------------------------------------- */
 /*                       Built-in scheme registrations                        */
 /* -------------------------------------------------------------------------- */
@@ -189,6 +503,21 @@ func init() {
 
 	// Shell commands
 	RegisterScheme(shellFactory, "shell", "sh")
+
+	// Files at a git ref
+	RegisterScheme(gitBlobFactory, "git")
+
+	// Diffs from git
+	RegisterScheme(gitDiffFactory, "gitdiff")
+
+	// Environment variables
+	RegisterScheme(envFactory, "env")
+
+	// Web pages as Markdown
+	RegisterScheme(htmlFactory, "html")
+
+	// rendered templates – template:path/to/file.md
+	RegisterScheme(templateFactory, "template")
 }
 
 /* -------------------------------------------------------------------------- */
@@ -197,23 +526,62 @@ func init() {
 
 // LoadContentSources concatenates all resolved sources, inserting two newlines between
 // each chunk (classic e-mail / Markdown style).
-func LoadContentSources(ctx context.Context, specs []string) (string, error) {
+//
+// Sources are loaded in parallel, at most runtime.NumCPU() at a time, but
+// are joined in the order given. The first error cancels the remaining loads
+// and is returned.
+//
+// If a CacheStore is given, every source except a NoCacheLoader is read
+// through it, keyed by its spec.
+func LoadContentSources(ctx context.Context, specs []string, cache ...*CacheStore) (string, error) {
+	return LoadContentSourcesTransformed(ctx, specs, "", cache...)
+}
+
+// LoadContentSourcesTransformed is LoadContentSources with each source piped
+// through the shell command transform (see TransformLoader) before the
+// results are joined. An empty transform leaves the sources as loaded.
+func LoadContentSourcesTransformed(ctx context.Context, specs []string, transform string, cache ...*CacheStore) (string, error) {
 	if len(specs) == 0 {
 		return "", nil
 	}
 
-	var parts []string
-	for _, raw := range specs {
+	var store *CacheStore
+	if len(cache) > 0 {
+		store = cache[0]
+	}
+
+	loaders := make([]ContentLoader, len(specs))
+	for i, raw := range specs {
 		loader, err := pickLoader(raw)
 		if err != nil {
 			return "", err
 		}
-		text, err := loader.Load(ctx)
-		if err != nil {
-			return "", err
+		if _, skip := loader.(*NoCacheLoader); store != nil && !skip {
+			loader = &CachedLoader{Inner: loader, TTL: store.TTL, Key: raw, Store: store}
 		}
-		parts = append(parts, text)
+		if transform != "" {
+			loader = &TransformLoader{Inner: loader, Command: transform}
+		}
+		loaders[i] = loader
+	}
+
+	parts := make([]string, len(loaders))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.NumCPU())
+	for i, loader := range loaders {
+		g.Go(func() error {
+			text, err := loader.Load(ctx)
+			if err != nil {
+				return err
+			}
+			parts[i] = text
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return "", err
 	}
+
 	return strings.Join(parts, "\n\n"), nil
 }
 
@@ -3,11 +3,17 @@ package render
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -26,6 +32,46 @@ func withTempFile(t *testing.T, contents string) string {
 	return fp
 }
 
+// initGitRepo creates a temporary git repository with one commit per entry
+// in commits, each writing the given files. It skips the test if git is not
+// installed.
+func initGitRepo(t *testing.T, commits ...map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	git("init", "-q")
+	for i, files := range commits {
+		for name, contents := range files {
+			fp := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(fp, []byte(contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+	}
+	return dir
+}
+
 // -----------------------------------------------------------------------------
 // pickLoader dispatch
 // -----------------------------------------------------------------------------
@@ -107,6 +153,26 @@ func TestLoadAll_Concatenation(t *testing.T) {
 	assert.Equal(want, got)
 }
 
+func TestTransformLoader(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	upper := &TransformLoader{Inner: &LiteralLoader{Text: "shout"}, Command: "tr a-z A-Z"}
+	out, err := upper.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("SHOUT", out)
+
+	// each source is transformed separately, then joined
+	got, err := LoadContentSourcesTransformed(ctx, []string{"text:first", "text:last"}, "tr a-z A-Z")
+	assert.NoError(err)
+	assert.Equal("FIRST\n\nLAST", got)
+
+	failing := &TransformLoader{Inner: &LiteralLoader{Text: "x"}, Command: "echo bad >&2; exit 3"}
+	_, err = failing.Load(ctx)
+	assert.ErrorContains(err, "content transform")
+	assert.ErrorContains(err, "bad")
+}
+
 // -----------------------------------------------------------------------------
 // Home-dir expansion (“~/…”) for file paths
 // -----------------------------------------------------------------------------
@@ -141,3 +207,367 @@ func TestPickLoader_Shell(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal("shell works\n", out)
 }
+
+func TestPickLoader_GitBlob(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	dir := initGitRepo(t,
+		map[string]string{"pkg/foo.go": "v1"},
+		map[string]string{"pkg/foo.go": "v2"},
+	)
+
+	ld, err := pickLoader("git:HEAD~1:pkg/foo.go")
+	assert.NoError(err)
+	gl, ok := ld.(*GitBlobLoader)
+	assert.True(ok, "expected loader to be *GitBlobLoader")
+	assert.Equal("HEAD~1", gl.Ref)
+	assert.Equal("pkg/foo.go", gl.Path)
+
+	gl.Dir = dir
+	out, err := gl.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("v1", out)
+
+	out, err = (&GitBlobLoader{Ref: "HEAD", Path: "pkg/foo.go", Dir: dir}).Load(ctx)
+	assert.NoError(err)
+	assert.Equal("v2", out)
+
+	// unknown ref
+	_, err = (&GitBlobLoader{Ref: "nope", Path: "pkg/foo.go", Dir: dir}).Load(ctx)
+	assert.Error(err)
+	assert.Contains(err.Error(), "git: cannot read pkg/foo.go at nope")
+
+	// without a Dir, git runs in the WithGitDir directory
+	out, err = LoadContentSources(WithGitDir(ctx, dir), []string{"git:HEAD~1:pkg/foo.go"})
+	assert.NoError(err)
+	assert.Equal("v1", out)
+
+	// malformed specs
+	for _, spec := range []string{"git:HEAD", "git::foo.go", "git:HEAD:"} {
+		_, err = pickLoader(spec)
+		assert.Error(err, spec)
+	}
+}
+
+func TestPickLoader_Env(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	t.Setenv("VIBE_TEST_SET", "project-x")
+	t.Setenv("VIBE_TEST_EMPTY", "")
+
+	// set
+	ld, err := pickLoader("env:VIBE_TEST_SET")
+	assert.NoError(err)
+	out, err := ld.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("project-x", out)
+
+	// set but empty is not an error
+	ld, err = pickLoader("env:VIBE_TEST_EMPTY")
+	assert.NoError(err)
+	out, err = ld.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("", out)
+
+	// unset
+	ld, err = pickLoader("env:VIBE_TEST_UNSET")
+	assert.NoError(err)
+	_, err = ld.Load(ctx)
+	assert.EqualError(err, "environment variable VIBE_TEST_UNSET is not set")
+
+	// unset, optional
+	ld, err = pickLoader("env:VIBE_TEST_UNSET?")
+	assert.NoError(err)
+	out, err = ld.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("", out)
+
+	// optional but set
+	ld, err = pickLoader("env:VIBE_TEST_SET?")
+	assert.NoError(err)
+	out, err = ld.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("project-x", out)
+
+	_, err = pickLoader("env:")
+	assert.Error(err)
+}
+
+// countingLoader counts how often it is loaded.
+type countingLoader struct {
+	calls atomic.Int32
+	text  string
+}
+
+func (l *countingLoader) Load(ctx context.Context) (string, error) {
+	l.calls.Add(1)
+	return l.text, nil
+}
+
+func TestCachedLoader(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	inner := &countingLoader{text: "slow"}
+	ld := &CachedLoader{Inner: inner, TTL: time.Hour}
+
+	for i := 0; i < 2; i++ {
+		out, err := ld.Load(ctx)
+		assert.NoError(err)
+		assert.Equal("slow", out)
+	}
+	assert.Equal(int32(1), inner.calls.Load())
+
+	// expired entries are fetched again
+	short := &CachedLoader{Inner: inner, TTL: 10 * time.Millisecond}
+	_, _ = short.Load(ctx)
+	time.Sleep(20 * time.Millisecond)
+	_, _ = short.Load(ctx)
+	assert.Equal(int32(3), inner.calls.Load())
+
+	// loaders sharing a store and key share the value
+	store := NewCacheStore(time.Hour)
+	a := &CachedLoader{Inner: &countingLoader{text: "a"}, TTL: store.TTL, Key: "k", Store: store}
+	b := &CachedLoader{Inner: &countingLoader{text: "b"}, TTL: store.TTL, Key: "k", Store: store}
+	out, _ := a.Load(ctx)
+	assert.Equal("a", out)
+	out, _ = b.Load(ctx)
+	assert.Equal("a", out)
+}
+
+func TestLoadContentSources_Cache(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	inner := &countingLoader{text: "fetched"}
+	RegisterScheme(func(string) (ContentLoader, error) { return inner, nil }, "test-cached")
+	RegisterScheme(func(string) (ContentLoader, error) { return &NoCacheLoader{Inner: inner}, nil }, "test-nocache")
+	t.Cleanup(func() {
+		delete(loaderRegistry, "test-cached")
+		delete(loaderRegistry, "test-nocache")
+	})
+
+	store := NewCacheStore(time.Hour)
+	for i := 0; i < 2; i++ {
+		out, err := LoadContentSources(ctx, []string{"test-cached:x"}, store)
+		assert.NoError(err)
+		assert.Equal("fetched", out)
+	}
+	assert.Equal(int32(1), inner.calls.Load())
+
+	// NoCacheLoader bypasses the store
+	for i := 0; i < 2; i++ {
+		_, err := LoadContentSources(ctx, []string{"test-nocache:x"}, store)
+		assert.NoError(err)
+	}
+	assert.Equal(int32(3), inner.calls.Load())
+
+	// without a store nothing is cached
+	_, err := LoadContentSources(ctx, []string{"test-cached:x"})
+	assert.NoError(err)
+	assert.Equal(int32(4), inner.calls.Load())
+}
+
+// barrierLoader blocks until n barrierLoaders are loading at once.
+type barrierLoader struct {
+	text    string
+	n       int32
+	running *atomic.Int32
+}
+
+func (l *barrierLoader) Load(ctx context.Context) (string, error) {
+	l.running.Add(1)
+	deadline := time.After(2 * time.Second)
+	for l.running.Load() < l.n {
+		select {
+		case <-deadline:
+			return "", fmt.Errorf("loaders did not run in parallel")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return l.text, nil
+}
+
+func TestLoadContentSources_Parallel(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	n := min(runtime.NumCPU(), 4)
+	if n < 2 {
+		t.Skip("needs at least 2 CPUs")
+	}
+
+	var running atomic.Int32
+	RegisterScheme(func(arg string) (ContentLoader, error) {
+		return &barrierLoader{text: arg[len("test-slow:"):], n: int32(n), running: &running}, nil
+	}, "test-slow")
+	t.Cleanup(func() { delete(loaderRegistry, "test-slow") })
+
+	var specs, want []string
+	for i := 0; i < n; i++ {
+		specs = append(specs, fmt.Sprintf("test-slow:%d", i))
+		want = append(want, fmt.Sprint(i))
+	}
+
+	got, err := LoadContentSources(ctx, specs)
+	assert.NoError(err)
+	assert.Equal(strings.Join(want, "\n\n"), got)
+
+	// the first error is returned
+	_, err = LoadContentSources(ctx, []string{"text:ok", "env:VIBE_TEST_UNSET_PARALLEL"})
+	assert.EqualError(err, "environment variable VIBE_TEST_UNSET_PARALLEL is not set")
+}
+
+func TestPickLoader_HTML(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	pages := map[string]string{
+		"/main": `<html><head><title>T</title><script>var x = 1;</script></head><body>
+<nav>Site nav</nav>
+<main><h1>Main Heading</h1><p>Some <strong>bold</strong> text.</p></main>
+<footer>Footer</footer>
+</body></html>`,
+		"/article": `<html><body><nav>Site nav</nav><article><h2>Article Heading</h2></article></body></html>`,
+		"/body":    `<html><body><h1>Body Heading</h1><ul><li>one</li></ul></body></html>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer srv.Close()
+
+	load := func(path string) (string, error) {
+		ld, err := pickLoader("html:" + srv.URL + path)
+		assert.NoError(err)
+		return ld.Load(ctx)
+	}
+
+	out, err := load("/main")
+	assert.NoError(err)
+	assert.Contains(out, "# Main Heading")
+	assert.Contains(out, "**bold**")
+	assert.NotContains(out, "Site nav")
+	assert.NotContains(out, "Footer")
+
+	out, err = load("/article")
+	assert.NoError(err)
+	assert.Contains(out, "## Article Heading")
+	assert.NotContains(out, "Site nav")
+
+	out, err = load("/body")
+	assert.NoError(err)
+	assert.Contains(out, "# Body Heading")
+	assert.Contains(out, "- one")
+
+	_, err = load("/missing")
+	assert.Error(err)
+}
+
+func TestPickLoader_GitDiff(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	dir := initGitRepo(t,
+		map[string]string{"a.txt": "one\n"},
+		map[string]string{"a.txt": "two\n"},
+	)
+
+	// stage a change
+	assert.NoError(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("three\n"), 0o644))
+	cmd := exec.Command("git", "add", "a.txt")
+	cmd.Dir = dir
+	assert.NoError(cmd.Run())
+
+	load := func(spec string) (string, error) {
+		ld, err := pickLoader(spec)
+		assert.NoError(err)
+		gl, ok := ld.(*GitDiffLoader)
+		assert.True(ok, "expected loader to be *GitDiffLoader")
+		gl.Dir = dir
+		return gl.Load(ctx)
+	}
+
+	out, err := load("gitdiff:staged")
+	assert.NoError(err)
+	assert.Contains(out, "-two\n+three")
+
+	out, err = load("gitdiff:HEAD~1..HEAD")
+	assert.NoError(err)
+	assert.Contains(out, "-one\n+two")
+
+	out, err = load("gitdiff:HEAD~1")
+	assert.NoError(err)
+	assert.Contains(out, "-one\n+three")
+
+	// nothing unstaged
+	out, err = load("gitdiff")
+	assert.NoError(err)
+	assert.Equal("", out)
+
+	_, err = load("gitdiff:nope")
+	assert.Error(err)
+
+	// without a Dir, git runs in the WithGitDir directory
+	out, err = LoadContentSources(WithGitDir(ctx, dir), []string{"gitdiff:staged"})
+	assert.NoError(err)
+	assert.Contains(out, "-two\n+three")
+
+	// not a repository
+	_, err = (&GitDiffLoader{Rev: "staged", Dir: t.TempDir()}).Load(ctx)
+	assert.Error(err)
+	assert.Contains(err.Error(), "not a git repository")
+}
+
+func TestTemplateLoader(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"outer.md": `---
+layout = "layout.md"
+---
+outer({{ partial "./parts/inner.md" }})`,
+		"parts/inner.md": `inner {{ .Data.Name }}`,
+		"layout.md":      `LAYOUT {{ .Content }}`,
+		"once.md":        `once({{ includeOnce "parts/inner.md" }})`,
+	})
+	r := NewRenderer(NewResolver("", repoFS), nil)
+	ctx := WithTemplateRenderer(context.Background(), r, &AnyContent{Data: map[string]string{"Name": "World"}})
+
+	// nested partials render; the layout is dropped as with "partial"
+	out, err := LoadContentSources(ctx, []string{"template:outer.md", "text:tail", "template:parts/inner.md"}, NewCacheStore(time.Hour))
+	assert.NoError(err)
+	assert.Equal("outer(inner World)\n\ntail\n\ninner World", out)
+
+	// an explicit Renderer wins over the context
+	l := &TemplateLoader{Renderer: r, Data: &AnyContent{Data: map[string]string{"Name": "there"}}, Path: "parts/inner.md"}
+	out, err = l.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("inner there", out)
+
+	// the load context stops the render
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = l.Load(cancelled)
+	assert.ErrorIs(err, context.Canceled)
+
+	// files already included once stay included
+	r.included = map[string]bool{"parts/inner.md": true}
+	once := &TemplateLoader{Renderer: r, Path: "once.md"}
+	out, err = once.Load(ctx)
+	assert.NoError(err)
+	assert.Equal("once()", out)
+	assert.True(r.included["parts/inner.md"])
+
+	_, err = LoadContentSources(context.Background(), []string{"template:outer.md"})
+	assert.ErrorContains(err, "no renderer available")
+
+	_, err = LoadContentSources(ctx, []string{"template:"})
+	assert.ErrorContains(err, "expected template:path")
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// Dependencies returns every file that rendering path may read: layouts,
+// before/after files, block overrides, imports, partials and includes, found
+// transitively. Paths are resolved file paths, topologically sorted so that
+// each file comes after the files it depends on; path itself is last.
+//
+// Only partial/include calls with a string literal path can be followed.
+// A cycle among templates is reported as an error that includes the cycle.
+func (r *Resolver) Dependencies(path string) ([]string, error) {
+	d := &depWalker{
+		r:      r,
+		state:  make(map[string]int),
+		listed: make(map[string]bool),
+	}
+	if err := d.visit(path, nil, true); err != nil {
+		return nil, err
+	}
+	return d.order, nil
+}
+
+const (
+	depVisiting = iota + 1
+	depDone
+)
+
+// depWalker performs a depth-first walk of template references.
+type depWalker struct {
+	r      *Resolver
+	state  map[string]int // templates: depVisiting or depDone
+	listed map[string]bool
+	stack  []string
+	order  []string
+}
+
+// depRef is a reference from one template to another file.
+type depRef struct {
+	path     string
+	template bool // false for files that are included raw
+}
+
+func (d *depWalker) list(filePath string) {
+	if !d.listed[filePath] {
+		d.listed[filePath] = true
+		d.order = append(d.order, filePath)
+	}
+}
+
+func (d *depWalker) visit(path string, cur *Template, isTemplate bool) error {
+	fsys, filePath, err := d.r.ResolvePartialPath(path, cur)
+	if err != nil {
+		return fmt.Errorf("error resolving dependency %q: %w", path, err)
+	}
+
+	if !isTemplate {
+		d.list(filePath)
+		return nil
+	}
+
+	switch d.state[filePath] {
+	case depVisiting:
+		return fmt.Errorf("dependency cycle detected: %s -> %s", strings.Join(d.stack, " -> "), filePath)
+	case depDone:
+		return nil
+	}
+
+	tmpl, err := LoadTemplateFS(filePath, fsys)
+	if err != nil {
+		return err
+	}
+	// Mirror Resolver.LoadTemplate, so relative paths resolve as they do
+	// during rendering.
+	tmpl.Path = path
+
+	d.state[filePath] = depVisiting
+	d.stack = append(d.stack, filePath)
+
+	refs, err := templateRefs(tmpl)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if err := d.visit(ref.path, tmpl, ref.template); err != nil {
+			return err
+		}
+	}
+
+	d.stack = d.stack[:len(d.stack)-1]
+	d.state[filePath] = depDone
+	d.list(filePath)
+	return nil
+}
+
+// templateRefs lists the files directly referenced by t.
+func templateRefs(t *Template) ([]depRef, error) {
+	var refs []depRef
+
+	for _, lp := range splitSemicolon(t.FrontMatter.Layout) {
+		refs = append(refs, depRef{path: lp, template: true})
+	}
+	for _, f := range append(splitSemicolon(t.FrontMatter.Before), splitSemicolon(t.FrontMatter.After)...) {
+		if p, ok := strings.CutPrefix(f, "!"); ok {
+			refs = append(refs, depRef{path: p, template: true})
+		} else {
+			refs = append(refs, depRef{path: f})
+		}
+	}
+
+	for _, ip := range t.FrontMatter.Import {
+		refs = append(refs, depRef{path: ip, template: true})
+	}
+
+	blocks, err := parseBlocks(t.FrontMatter.Block)
+	if err != nil {
+		return nil, fmt.Errorf("error in %s: %w", t.Path, err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(blocks)) {
+		refs = append(refs, depRef{path: blocks[name], template: true})
+	}
+
+	tree := parse.New("content")
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(t.Body, "", "", trees); err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %w", t.Path, err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(trees)) {
+		templateCalls(trees[name].Root, func(name, arg string, _ parse.Pos) {
+			isTemplate := name == "partial" || name == "partialWith"
+			refs = append(refs, depRef{path: arg, template: isTemplate})
+		})
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestDependencies(t *testing.T) {
+	systemFS := createTestFS(map[string]string{
+		"vibe/coder.md":  "system {{ include \"<vibe/rules.txt>\" }}",
+		"vibe/rules.txt": "RULES",
+	})
+	repoFS := createTestFS(map[string]string{
+		"main.md": "---toml\nlayout = \"layouts/base.md\"\nbefore = \"!header.md; raw.txt\"\n" +
+			"block = \"side=parts/side.md\"\n---\n" +
+			"{{ partial \"<vibe/coder>\" }}{{ if .X }}{{ partialWith \"parts/item.md\" .X }}{{ end }}",
+		"layouts/base.md":  "---toml\nlayout = \"layouts/outer.md\"\n---\n{{ block \"side\" . }}{{ end }}{{ .Content }}",
+		"layouts/outer.md": "{{ .Content }}",
+		"header.md":        "{{ partial \"parts/item.md\" }}",
+		"raw.txt":          "{{ partial \"ignored.md\" }}",
+		"parts/side.md":    "side",
+		"parts/item.md":    "{{ includeOnce \"raw.txt\" }}",
+
+		"cycle_a.md": "{{ partial \"cycle_b.md\" }}",
+		"cycle_b.md": "---toml\nlayout = \"cycle_a.md\"\n---\nB",
+
+		"broken.md": "{{ partial \"missing.md\" }}",
+	})
+
+	resolver := NewResolver("", repoFS, systemFS)
+
+	t.Run("transitive and sorted", func(t *testing.T) {
+		assert := assert.New(t)
+		deps, err := resolver.Dependencies("main.md")
+		assert.NoError(err)
+		assert.Equal([]string{
+			"layouts/outer.md",
+			"layouts/base.md",
+			"raw.txt",
+			"parts/item.md",
+			"header.md",
+			"parts/side.md",
+			"vibe/rules.txt",
+			"vibe/coder.md",
+			"main.md",
+		}, deps)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := resolver.Dependencies("cycle_a.md")
+		assert.Error(err)
+		assert.Contains(err.Error(), "dependency cycle detected: cycle_a.md -> cycle_b.md -> cycle_a.md")
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := resolver.Dependencies("broken.md")
+		assert.Error(err)
+		assert.Contains(err.Error(), `"missing.md"`)
+	})
+}
@@ -2,9 +2,11 @@ package render
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
 // RawFrontMatter represents the raw front matter content and tag
@@ -87,3 +89,45 @@ func ParseToml(content string, v interface{}) error {
 	}
 	return nil
 }
+
+// ParseYaml parses YAML content into the provided structure
+func ParseYaml(content string, v interface{}) error {
+	if err := yaml.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return nil
+}
+
+// reTomlLine matches a TOML table header or key/value assignment.
+var reTomlLine = regexp.MustCompile(`^(\[|[A-Za-z0-9_."'-]+\s*=)`)
+
+// ParseFrontMatterContent parses raw front matter into v, choosing the
+// format by tag ("toml", "yaml"/"yml"). Any other tag, including none, infers
+// the format from the first meaningful line: TOML assignments and tables stay
+// TOML, anything else is treated as YAML.
+func ParseFrontMatterContent(tag, content string, v interface{}) error {
+	switch strings.ToLower(tag) {
+	case "toml":
+		return ParseToml(content, v)
+	case "yaml", "yml":
+		return ParseYaml(content, v)
+	default:
+		if isTomlContent(content) {
+			return ParseToml(content, v)
+		}
+		return ParseYaml(content, v)
+	}
+}
+
+// isTomlContent reports whether the first non-blank, non-comment line
+// looks like TOML.
+func isTomlContent(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return reTomlLine.MatchString(line)
+	}
+	return true
+}
@@ -90,3 +90,57 @@ without closing delimiter`,
 		})
 	}
 }
+
+func TestYamlFrontMatter(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"tagged.md":    "---yaml\nlayout: base.md\nselect: \"*.go\"\ndirtree: cmd/;internal/\n---\nTagged",
+		"yml.md":       "---yml\nlayout: base.md\n---\nYml",
+		"bare.md":      "---\n# comment first\nlayout: base.md\nselect: .go$\n---\nBare",
+		"bare_toml.md": "---\nlayout = \"base.md\"\n---\nBare TOML",
+		"bad.md":       "---yaml\nlayout: [unclosed\n---\nBad",
+		"unknown.md":   "---json\n{\"layout\": \"base.md\"}\n---\nUnknown",
+		"custom.md":    "---vibe\nlayout = \"base.md\"\n---\nCustom",
+		"bad_tag.md":   "---vibe\nlayout = [unclosed\n---\nBad",
+	})
+	ctx := NewResolver("", repoFS)
+
+	tmpl, err := ctx.LoadTemplate("tagged.md", nil)
+	assert.NoError(err)
+	assert.Equal("base.md", tmpl.FrontMatter.Layout)
+	assert.Equal("*.go", tmpl.FrontMatter.Select)
+	assert.Equal("cmd/;internal/", tmpl.FrontMatter.Dirtree)
+	assert.Equal("Tagged", tmpl.Body)
+
+	tmpl, err = ctx.LoadTemplate("yml.md", nil)
+	assert.NoError(err)
+	assert.Equal("base.md", tmpl.FrontMatter.Layout)
+
+	// Untagged front matter infers the format from its content
+	tmpl, err = ctx.LoadTemplate("bare.md", nil)
+	assert.NoError(err)
+	assert.Equal("base.md", tmpl.FrontMatter.Layout)
+	assert.Equal(".go$", tmpl.FrontMatter.Select)
+
+	tmpl, err = ctx.LoadTemplate("bare_toml.md", nil)
+	assert.NoError(err)
+	assert.Equal("base.md", tmpl.FrontMatter.Layout)
+
+	_, err = ctx.LoadTemplate("bad.md", nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to parse YAML")
+
+	// Unknown tags fall back to inferring the format
+	tmpl, err = ctx.LoadTemplate("unknown.md", nil)
+	assert.NoError(err)
+	assert.Equal("base.md", tmpl.FrontMatter.Layout)
+
+	tmpl, err = ctx.LoadTemplate("custom.md", nil)
+	assert.NoError(err)
+	assert.Equal("base.md", tmpl.FrontMatter.Layout)
+
+	_, err = ctx.LoadTemplate("bad_tag.md", nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to parse TOML")
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultFuncs returns a small set of string helpers for templates. They are
+// not registered by default; opt in with Renderer.RegisterFuncs(DefaultFuncs()).
+// dict is the exception: it is always available, for use with partialWith.
+//
+// All helpers take the piped value as their last argument, so they compose
+// naturally: {{ .Content | trimSpace | indent 2 }}.
+func DefaultFuncs() template.FuncMap {
+	return template.FuncMap{
+		"trimSpace": strings.TrimSpace,
+		"indent":    indent,
+		"wrap":      wrap,
+		"titleCase": titleCase,
+		"toJSON":    toJSON,
+		"dict":      dict,
+	}
+}
+
+// indent prefixes every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, ln := range lines {
+		if ln != "" {
+			lines[i] = pad + ln
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap re-flows each paragraph of s so that no line exceeds width runes,
+// breaking on whitespace. Words longer than width are left intact.
+func wrap(width int, s string) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, ln := range lines {
+		words := strings.Fields(ln)
+		if len(words) == 0 {
+			out = append(out, "")
+			continue
+		}
+
+		var cur strings.Builder
+		curLen := 0
+		for _, word := range words {
+			wl := utf8.RuneCountInString(word)
+			if curLen > 0 && curLen+1+wl > width {
+				out = append(out, cur.String())
+				cur.Reset()
+				curLen = 0
+			}
+			if curLen > 0 {
+				cur.WriteByte(' ')
+				curLen++
+			}
+			cur.WriteString(word)
+			curLen += wl
+		}
+		out = append(out, cur.String())
+	}
+	return strings.Join(out, "\n")
+}
+
+// titleCase upper-cases the first letter of every whitespace-separated word.
+func titleCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	atStart := true
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			atStart = true
+			b.WriteRune(r)
+			continue
+		}
+		if atStart {
+			r = unicode.ToUpper(r)
+			atStart = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// dict builds a map from alternating keys and values, e.g. for passing
+// ad-hoc data to partialWith: (dict "Name" "x" "Count" 2).
+func dict(kv ...any) (map[string]any, error) {
+	if len(kv)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]any, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", kv[i])
+		}
+		m[key] = kv[i+1]
+	}
+	return m, nil
+}
+
+// toJSON marshals v as compact JSON.
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestDefaultFuncs(t *testing.T) {
+	assert := assert.New(t)
+
+	funcs := DefaultFuncs()
+	for _, name := range []string{"trimSpace", "indent", "wrap", "titleCase", "toJSON", "dict"} {
+		assert.Contains(funcs, name)
+	}
+	assert.Equal("hello", funcs["trimSpace"].(func(string) string)("  hello \n"))
+
+	assert.Equal("  a\n  b\n\n  c", indent(2, "a\nb\n\nc"))
+
+	assert.Equal("the quick\nbrown fox\njumps", wrap(10, "the quick brown fox jumps"))
+	assert.Equal("a\n\nb c", wrap(10, "a\n\nb   c"))
+	assert.Equal("unbreakable\nword", wrap(4, "unbreakable word"))
+
+	assert.Equal("Hello Big  World", titleCase("hello big  world"))
+
+	js, err := toJSON(map[string]any{"a": 1, "b": []string{"x"}})
+	assert.NoError(err)
+	assert.Equal(`{"a":1,"b":["x"]}`, js)
+
+	_, err = toJSON(func() {})
+	assert.Error(err)
+
+	m, err := dict("a", 1, "b", "x")
+	assert.NoError(err)
+	assert.Equal(map[string]any{"a": 1, "b": "x"}, m)
+
+	_, err = dict("a")
+	assert.Error(err)
+	_, err = dict(1, 2)
+	assert.Error(err)
+}
+
+func TestRendererRegisterFuncs(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"main.md":    `{{ "  padded  " | trimSpace | titleCase }}|{{ shout "hi" }}|{{ toJSON .Content }}`,
+		"indent.md":  `{{ "a\nb" | indent 4 }}`,
+		"partial.md": `{{ partial "child.md" }}`,
+		"child.md":   `child`,
+	})
+
+	ctx := NewResolver("", repoFS)
+	renderer := NewRenderer(ctx, nil)
+	renderer.RegisterFuncs(DefaultFuncs())
+	renderer.RegisterFunc("shout", strings.ToUpper)
+
+	out, err := renderer.Render("main.md", &testContent{content: "c"})
+	assert.NoError(err)
+	assert.Equal(`Padded|HI|"c"`, out)
+
+	out, err = renderer.Render("indent.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal("    a\n    b", out)
+
+	// Built-in helpers cannot be shadowed by registered functions.
+	renderer.RegisterFunc("partial", func(string) string { return "shadowed" })
+	out, err = renderer.Render("partial.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal("child", out)
+}
+
+func TestRendererWithoutRegisteredFuncs(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"main.md": `{{ "x" | trimSpace }}`,
+	})
+
+	renderer := NewRenderer(NewResolver("", repoFS), nil)
+	_, err := renderer.Render("main.md", &testContent{})
+	assert.Error(err)
+	assert.Contains(err.Error(), `function "trimSpace" not defined`)
+}
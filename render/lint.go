@@ -0,0 +1,340 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// LintError describes a problem found by Renderer.Lint.
+type LintError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Col     int    `json:"col,omitempty"` // 1-based; 0 when only the line is known
+	Message string `json:"message"`
+}
+
+func (e LintError) Error() string {
+	if e.Col > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Col, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// reParseError extracts the line and message from a text/template parse error.
+var reParseError = regexp.MustCompile(`^template: [^:]*:(\d+): (.*)$`)
+
+// Lint loads the template at path and statically checks it without
+// rendering. It reports:
+//   - front matter that fails to parse, or contains unknown fields
+//   - layouts, before, after, block and import files that cannot be resolved
+//   - layout cycles
+//   - template syntax errors (e.g. an unclosed {{ if }})
+//   - partial/include references that cannot be resolved
+func (r *Renderer) Lint(path string) []LintError {
+	fsys, filePath, err := r.ctx.ResolvePartialPath(path, nil)
+	if err != nil {
+		return []LintError{{File: path, Line: 1, Message: err.Error()}}
+	}
+
+	blob, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return []LintError{{File: path, Line: 1, Message: err.Error()}}
+	}
+	content := string(blob)
+
+	tag, rawFM, body, err := ParseFrontMatter(content)
+	if err != nil {
+		return []LintError{{File: path, Line: 1, Message: err.Error()}}
+	}
+
+	l := &linter{
+		r:          r,
+		file:       path,
+		cur:        &Template{Path: filePath, FS: fsys},
+		rawFM:      rawFM,
+		fmLine:     frontMatterStartLine(content),
+		bodyOffset: strings.Count(content[:len(content)-len(body)], "\n"),
+	}
+
+	if rawFM != "" {
+		l.lintFrontMatter(tag)
+	}
+	l.lintBody(body)
+
+	sort.SliceStable(l.errs, func(i, j int) bool { return l.errs[i].Line < l.errs[j].Line })
+	return l.errs
+}
+
+// linter accumulates problems for a single template file.
+type linter struct {
+	r          *Renderer
+	file       string
+	cur        *Template // template being linted, for relative resolution
+	rawFM      string
+	fmLine     int // file line of the first front matter line
+	bodyOffset int // number of file lines before the body
+	errs       []LintError
+}
+
+func (l *linter) add(line int, format string, args ...any) {
+	l.errs = append(l.errs, LintError{File: l.file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// keyLine returns the file line on which a front matter key is set.
+func (l *linter) keyLine(key string) int {
+	for i, ln := range strings.Split(l.rawFM, "\n") {
+		ln = strings.TrimSpace(ln)
+		if rest, ok := strings.CutPrefix(ln, key); ok {
+			rest = strings.TrimSpace(rest)
+			if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":") {
+				return l.fmLine + i
+			}
+		}
+	}
+	return l.fmLine
+}
+
+func (l *linter) lintFrontMatter(tag string) {
+	var raw map[string]any
+	if err := ParseFrontMatterContent(tag, l.rawFM, &raw); err != nil {
+		l.add(l.fmLine, "%v", err)
+		return
+	}
+
+	known := frontMatterKeys()
+	var unknown []string
+	for key := range raw {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		msg := fmt.Sprintf("unknown front matter field %q", key)
+		if s := closestKey(key, known); s != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", s)
+		}
+		l.add(l.keyLine(key), "%s", msg)
+	}
+
+	var meta FrontMatter
+	if err := ParseFrontMatterContent(tag, l.rawFM, &meta); err != nil {
+		l.add(l.fmLine, "%v", err)
+		return
+	}
+
+	l.lintLayouts(meta.Layout, l.cur, []string{l.file}, l.keyLine("layout"))
+
+	blocks, err := parseBlocks(meta.Block)
+	if err != nil {
+		l.add(l.keyLine("block"), "%v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(blocks)) {
+		if _, _, err := l.r.ctx.ResolvePartialPath(blocks[name], l.cur); err != nil {
+			l.add(l.keyLine("block"), "block %s file %q not found", name, blocks[name])
+		}
+	}
+
+	for _, ip := range meta.Import {
+		if _, _, err := l.r.ctx.ResolvePartialPath(ip, l.cur); err != nil {
+			l.add(l.keyLine("import"), "import %q not found", ip)
+		}
+	}
+
+	for _, key := range []string{"before", "after"} {
+		files := meta.Before
+		if key == "after" {
+			files = meta.After
+		}
+		for _, f := range splitSemicolon(files) {
+			f = strings.TrimPrefix(f, "!")
+			if _, _, err := l.r.ctx.ResolvePartialPath(f, l.cur); err != nil {
+				l.add(l.keyLine(key), "%s file %q not found", key, f)
+			}
+		}
+	}
+}
+
+// lintLayouts follows the layout chain the same way renderTemplateInternal
+// does, reporting missing layouts and cycles.
+func (l *linter) lintLayouts(layout string, cur *Template, stack []string, line int) {
+	for _, lp := range splitSemicolon(layout) {
+		for _, s := range stack {
+			if s == lp {
+				l.add(line, "layout cycle detected: %s -> %s", strings.Join(stack, " -> "), lp)
+				return
+			}
+		}
+		if len(stack) > 10 {
+			l.add(line, "layout nesting too deep (max 10): %s", lp)
+			return
+		}
+
+		wrapper, err := l.r.ctx.LoadTemplate(lp, cur)
+		if err != nil {
+			l.add(line, "layout %q not found", lp)
+			continue
+		}
+		l.lintLayouts(wrapper.FrontMatter.Layout, wrapper, append(stack, lp), line)
+	}
+}
+
+func (l *linter) lintBody(body string) {
+	tmpl, err := template.New("content").Funcs(l.r.funcMap(context.Background(), nil)).Parse(body)
+	if err != nil {
+		if m := reParseError.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			l.add(l.bodyOffset+line, "%s", m[2])
+		} else {
+			l.add(l.bodyOffset+1, "%v", err)
+		}
+		return
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		templateCalls(t.Tree.Root, func(name, arg string, pos parse.Pos) {
+			if _, _, err := l.r.ctx.ResolvePartialPath(arg, l.cur); err != nil {
+				line := l.bodyOffset + strings.Count(body[:pos], "\n") + 1
+				col := int(pos) - strings.LastIndex(body[:pos], "\n")
+				l.errs = append(l.errs, LintError{
+					File:    l.file,
+					Line:    line,
+					Col:     col,
+					Message: fmt.Sprintf("%s %q not found", name, arg),
+				})
+			}
+		})
+	}
+}
+
+// templateCalls calls fn for every partial, partialWith, include or
+// includeOnce call in the tree rooted at n whose path is a string literal.
+func templateCalls(n parse.Node, fn func(name, arg string, pos parse.Pos)) {
+	walkCommands(n, func(cmd *parse.CommandNode) {
+		if len(cmd.Args) < 2 {
+			return
+		}
+		ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+		if !ok {
+			return
+		}
+		switch ident.Ident {
+		case "partial", "partialWith", "include", "includeOnce":
+		default:
+			return
+		}
+		if arg, ok := cmd.Args[1].(*parse.StringNode); ok {
+			fn(ident.Ident, arg.Text, cmd.Position())
+		}
+	})
+}
+
+// walkCommands calls fn for every command node reachable from n.
+func walkCommands(n parse.Node, fn func(*parse.CommandNode)) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkCommands(c, fn)
+		}
+	case *parse.ActionNode:
+		walkCommands(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkCommands(c, fn)
+		}
+	case *parse.CommandNode:
+		fn(n)
+		for _, a := range n.Args {
+			walkCommands(a, fn)
+		}
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkCommands(n.Pipe, fn)
+	}
+}
+
+func walkBranch(b *parse.BranchNode, fn func(*parse.CommandNode)) {
+	walkCommands(b.Pipe, fn)
+	walkCommands(b.List, fn)
+	walkCommands(b.ElseList, fn)
+}
+
+// frontMatterStartLine returns the 1-based file line of the first line
+// inside the front matter block.
+func frontMatterStartLine(content string) int {
+	for i, ln := range strings.Split(content, "\n") {
+		if strings.TrimSpace(ln) != "" {
+			return i + 2
+		}
+	}
+	return 1
+}
+
+// frontMatterKeys returns the set of keys accepted in front matter.
+func frontMatterKeys() map[string]bool {
+	keys := map[string]bool{}
+	t := reflect.TypeOf(FrontMatter{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if name != "" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// closestKey returns the known key within edit distance 2 of key, if any.
+func closestKey(key string, known map[string]bool) string {
+	best, bestDist := "", 3
+	for k := range known {
+		if d := editDistance(key, k); d < bestDist || (d == bestDist && k < best) {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestLint(t *testing.T) {
+	systemFS := createTestFS(map[string]string{
+		"vibe/coder.md": "system coder",
+	})
+	repoFS := createTestFS(map[string]string{
+		"ok.md": "---toml\nlayout = \"base.md\"\nbefore = \"!header.md\"\n---\n" +
+			"{{ partial \"<vibe/coder>\" }}\n{{ include \"./parts/footer\" }}",
+		"base.md":         "BASE {{ .Content }}",
+		"header.md":       "HEADER",
+		"parts/footer.md": "FOOTER",
+
+		"missing.md": "line one\n{{ if .X }}\n  {{ partial \"nope.md\" }}\n{{ end }}\n{{ range .Y }}{{ include \"@gone\" }}{{ end }}",
+
+		"typo.md": "---toml\nlayot = \"base.md\"\nselect = \".go\"\nflavour = \"x\"\n---\nbody",
+
+		"unclosed.md": "---toml\nselect = \".go\"\n---\nstart\n{{ if .X }}\nnever closed",
+
+		"cycle_a.md": "---toml\nlayout = \"cycle_b.md\"\n---\nA",
+		"cycle_b.md": "---toml\nlayout = \"cycle_a.md\"\n---\nB",
+
+		"bad_refs.md": "---\nlayout: missing_layout.md\nafter: missing_after.md\n---\nbody",
+
+		"bad_fm.md": "---toml\nlayout = \n---\nbody",
+
+		"bad_block.md": "---toml\nlayout = \"base.md\"\nblock = \"side=./nope.md; broken\"\n---\nbody",
+	})
+
+	renderer := NewRenderer(NewResolver("", repoFS, systemFS), nil)
+
+	t.Run("clean template", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Empty(renderer.Lint("ok.md"))
+	})
+
+	t.Run("undefined partial references", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("missing.md")
+		assert.Equal([]LintError{
+			{File: "missing.md", Line: 3, Col: 6, Message: `partial "nope.md" not found`},
+			{File: "missing.md", Line: 5, Col: 18, Message: `include "@gone" not found`},
+		}, errs)
+	})
+
+	t.Run("front matter typos", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("typo.md")
+		assert.Equal([]LintError{
+			{File: "typo.md", Line: 2, Message: `unknown front matter field "layot" (did you mean "layout"?)`},
+			{File: "typo.md", Line: 4, Message: `unknown front matter field "flavour"`},
+		}, errs)
+	})
+
+	t.Run("unclosed if", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("unclosed.md")
+		assert.Len(errs, 1)
+		assert.Equal(6, errs[0].Line)
+		assert.Contains(errs[0].Message, "unexpected EOF")
+	})
+
+	t.Run("layout cycle", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("cycle_a.md")
+		assert.Equal([]LintError{
+			{File: "cycle_a.md", Line: 2, Message: "layout cycle detected: cycle_a.md -> cycle_b.md -> cycle_a.md"},
+		}, errs)
+	})
+
+	t.Run("missing layout and after files", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("bad_refs.md")
+		assert.Equal([]LintError{
+			{File: "bad_refs.md", Line: 2, Message: `layout "missing_layout.md" not found`},
+			{File: "bad_refs.md", Line: 3, Message: `after file "missing_after.md" not found`},
+		}, errs)
+	})
+
+	t.Run("block overrides", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("bad_block.md")
+		assert.Equal([]LintError{
+			{File: "bad_block.md", Line: 3, Message: `invalid block override "broken", expected name=path`},
+		}, errs)
+	})
+
+	t.Run("invalid front matter", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("bad_fm.md")
+		assert.Len(errs, 1)
+		assert.Contains(errs[0].Message, "failed to parse TOML")
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		assert := assert.New(t)
+		errs := renderer.Lint("does_not_exist.md")
+		assert.Len(errs, 1)
+		assert.Contains(errs[0].Error(), "does_not_exist.md:1:")
+	})
+}
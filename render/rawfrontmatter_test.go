@@ -13,7 +13,7 @@ func TestRawFrontMatterCapture(t *testing.T) {
 		"complex.md": "---toml\nlayout=\"base.md\"\nselect=\"*.go\"\ndirtree=\"cmd/;internal/\"\n---\nContent",
 		"none.md":    "No frontmatter here",
 	})
-	ctx := NewResolver(repoFS)
+	ctx := NewResolver("", repoFS)
 	assert := assert.New(t)
 
 	// Test simple frontmatter capture
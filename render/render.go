@@ -4,11 +4,16 @@ package render
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
+	"reflect"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/hayeah/fork2/internal/metrics"
 )
@@ -25,6 +30,19 @@ func (cw *countingWriter) Write(p []byte) (n int, err error) {
 	return n, err
 }
 
+// ctxWriter fails every write once ctx is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw *ctxWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
+
 // Renderer provides template rendering capabilities.
 type Renderer struct {
 	ctx *Resolver
@@ -34,6 +52,21 @@ type Renderer struct {
 
 	// Metrics for tracking template usage
 	metrics *metrics.OutputMetrics
+
+	// Extra template functions available to every template
+	funcs template.FuncMap
+
+	// Maximum wall-clock time for a top-level render (0 = no limit)
+	timeout time.Duration
+
+	// Files already emitted by includeOnce during the current render
+	included map[string]bool
+
+	// Wrap each template's output in BEGIN/END comments
+	trace io.Writer
+
+	// Serialises renders started by TemplateLoader, which load in parallel
+	loadMu sync.Mutex
 }
 
 // NewRenderer creates a new Renderer with the given RenderContext and metrics.
@@ -44,6 +77,44 @@ func NewRenderer(ctx *Resolver, m *metrics.OutputMetrics) *Renderer {
 	}
 }
 
+// WithTimeout limits how long a single render may run. When the deadline
+// passes, rendering stops with an error wrapping context.DeadlineExceeded.
+//
+// The abandoned template keeps running in the background until its current
+// function call returns. Each render works on its own copy of the per-render
+// state, so the Renderer can be reused right away.
+func (r *Renderer) WithTimeout(d time.Duration) *Renderer {
+	r.timeout = d
+	return r
+}
+
+// WithTracing writes a trace of every render to w. Each template body,
+// including layouts and partials, gets a <!-- BEGIN template:path --> comment
+// when it starts and its output followed by <!-- END template:path --> when
+// it finishes, so a partial's block sits inside its caller's. The render
+// output itself is unchanged. A nil w disables tracing.
+func (r *Renderer) WithTracing(w io.Writer) *Renderer {
+	r.trace = w
+	return r
+}
+
+// RegisterFunc makes fn available to templates under name.
+func (r *Renderer) RegisterFunc(name string, fn any) {
+	if r.funcs == nil {
+		r.funcs = template.FuncMap{}
+	}
+	r.funcs[name] = fn
+}
+
+// RegisterFuncs makes every function in m available to templates.
+// See DefaultFuncs for an opt-in set of string helpers.
+func (r *Renderer) RegisterFuncs(m template.FuncMap) {
+	if r.funcs == nil {
+		r.funcs = template.FuncMap{}
+	}
+	maps.Copy(r.funcs, m)
+}
+
 // LoadTemplate loads a template from the given path.
 func (r *Renderer) LoadTemplate(path string) (*Template, error) {
 	return r.ctx.LoadTemplate(path, r.cur)
@@ -56,6 +127,25 @@ type Content interface {
 	SetContent(string) // setter (mutates receiver)
 }
 
+// AnyContent adapts arbitrary data to the Content interface so that it can
+// be passed to a partial. Templates reach the wrapped value as .Data.
+type AnyContent struct {
+	Data    any
+	content string
+}
+
+func (c *AnyContent) Content() string     { return c.content }
+func (c *AnyContent) SetContent(s string) { c.content = s }
+
+// asContent returns data unchanged if it already implements Content,
+// otherwise it wraps it in an AnyContent.
+func asContent(data any) Content {
+	if c, ok := data.(Content); ok {
+		return c
+	}
+	return &AnyContent{Data: data}
+}
+
 // RenderPartialTo renders a template without a layout to the provided writer.
 // It's a convenience method that calls RenderTo with an empty layoutPath.
 //
@@ -70,6 +160,14 @@ type Content interface {
 // Returns:
 //   - An error if the template could not be loaded or rendered
 func (r *Renderer) RenderPartialTo(w io.Writer, partialPath string, data Content) error {
+	return r.startRender(context.Background(), w, func(ctx context.Context, rr *Renderer, w io.Writer) error {
+		return rr.renderPartialTo(ctx, w, partialPath, data)
+	})
+}
+
+// renderPartialTo is RenderPartialTo bound to ctx, used for nested partials
+// that already run under the caller's deadline.
+func (r *Renderer) renderPartialTo(ctx context.Context, w io.Writer, partialPath string, data Content) error {
 	// Load the template
 	tmpl, err := r.LoadTemplate(partialPath)
 	if err != nil {
@@ -91,7 +189,8 @@ func (r *Renderer) RenderPartialTo(w io.Writer, partialPath string, data Content
 	tmpl.FrontMatter.Layout = ""
 
 	// Render the template
-	return r.RenderTemplateTo(w, tmpl, data)
+	seen := make(map[string]bool)
+	return r.renderTemplateInternal(ctx, w, tmpl, data, seen, 0, nil)
 }
 
 // RenderPartial renders a template without a layout.
@@ -116,6 +215,27 @@ func (r *Renderer) RenderPartial(partialPath string, data Content) (string, erro
 	return buf.String(), nil
 }
 
+// renderPartial is RenderPartial bound to ctx.
+func (r *Renderer) renderPartial(ctx context.Context, partialPath string, data Content) (string, error) {
+	var buf bytes.Buffer
+	err := r.renderPartialTo(ctx, &buf, partialPath, data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderPartialWithData renders a template without a layout, passing it data
+// instead of the enclosing template's data. Values that do not implement
+// Content are wrapped in an AnyContent. This backs the "partialWith" template
+// function:
+//
+//	{{ partialWith "./item.md" .Item }}
+//	{{ partialWith "./item.md" (dict "Name" "x") }}
+func (r *Renderer) RenderPartialWithData(partialPath string, data any) (string, error) {
+	return r.RenderPartial(partialPath, asContent(data))
+}
+
 // Include reads a file and returns its raw contents as a string.
 // The path resolution follows the same rules as templates, so callers can use
 // system (<vibe/foo>), repo (@foo/bar) and relative (./foo) paths.
@@ -125,6 +245,30 @@ func (r *Renderer) Include(path string) (string, error) {
 		return "", fmt.Errorf("error resolving include path %s: %w", path, err)
 	}
 
+	return r.readInclude(fsys, filePath)
+}
+
+// IncludeOnce is like Include, but returns an empty string if the same file
+// was already included by IncludeOnce earlier in the current render.
+func (r *Renderer) IncludeOnce(path string) (string, error) {
+	fsys, filePath, err := r.ctx.ResolvePartialPath(path, r.cur)
+	if err != nil {
+		return "", fmt.Errorf("error resolving include path %s: %w", path, err)
+	}
+
+	if r.included[filePath] {
+		return "", nil
+	}
+	if r.included == nil {
+		r.included = make(map[string]bool)
+	}
+	r.included[filePath] = true
+
+	return r.readInclude(fsys, filePath)
+}
+
+// readInclude reads a resolved include file and records its metrics.
+func (r *Renderer) readInclude(fsys fs.FS, filePath string) (string, error) {
 	b, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
 		return "", fmt.Errorf("error reading include %s: %w", filePath, err)
@@ -132,6 +276,7 @@ func (r *Renderer) Include(path string) (string, error) {
 
 	if r.metrics != nil {
 		r.metrics.Add("include", filePath, b)
+		r.metrics.AddSection("include", filePath, string(b))
 	}
 
 	return string(b), nil
@@ -164,10 +309,87 @@ func (r *Renderer) Render(contentPath string, data Content) (string, error) {
 	return buf.String(), nil
 }
 
+// RenderWithContext is like Render, but stops when ctx is done or the
+// renderer's timeout expires.
+func (r *Renderer) RenderWithContext(ctx context.Context, contentPath string, data Content) (string, error) {
+	tmpl, err := r.LoadTemplate(contentPath)
+	if err != nil {
+		return "", fmt.Errorf("error loading content template %s: %w", contentPath, err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderTemplateToContext(ctx, &buf, tmpl, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // RenderTemplateTo renders a template to the provided writer and applies any layouts specified in its metadata
 func (r *Renderer) RenderTemplateTo(w io.Writer, t *Template, data Content) error {
-	seen := make(map[string]bool)
-	return r.renderTemplateInternal(w, t, data, seen, 0)
+	return r.RenderTemplateToContext(context.Background(), w, t, data)
+}
+
+// RenderTemplateToContext is like RenderTemplateTo, but stops when ctx is
+// done or the renderer's timeout expires.
+func (r *Renderer) RenderTemplateToContext(ctx context.Context, w io.Writer, t *Template, data Content) error {
+	return r.startRender(ctx, w, func(ctx context.Context, rr *Renderer, w io.Writer) error {
+		seen := make(map[string]bool)
+		return rr.renderTemplateInternal(ctx, w, t, data, seen, 0, nil)
+	})
+}
+
+// startRender begins a top-level render: it gives render a fresh copy of the
+// renderer to keep per-render state in, applies the renderer timeout to ctx
+// and runs render in a goroutine, abandoning it if ctx is done first. Output
+// is buffered so that an abandoned render never writes to w, and its state
+// is never seen by later renders.
+func (r *Renderer) startRender(ctx context.Context, w io.Writer, render func(context.Context, *Renderer, io.Writer) error) error {
+	rr := r.forRender()
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	if ctx.Done() == nil {
+		return render(ctx, rr, w)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("render aborted: %w", err)
+	}
+
+	var buf bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- render(ctx, rr, &buf)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(buf.Bytes())
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("render aborted: %w", ctx.Err())
+	}
+}
+
+// forRender returns a copy of r for a single top-level render. Settings are
+// shared; the current template starts out as r's and the includeOnce set
+// starts out empty.
+func (r *Renderer) forRender() *Renderer {
+	return &Renderer{
+		ctx:      r.ctx,
+		cur:      r.cur,
+		metrics:  r.metrics,
+		funcs:    r.funcs,
+		timeout:  r.timeout,
+		included: make(map[string]bool),
+		trace:    r.trace,
+	}
 }
 
 // RenderTemplate renders a template and applies any layouts specified in its metadata
@@ -200,12 +422,12 @@ func splitSemicolon(s string) []string {
 // processFile handles loading and processing a file based on bang prefix
 // If the path starts with !, it's rendered as a template
 // Otherwise, it's included as raw content
-func (r *Renderer) processFile(path string, data Content) (string, error) {
+func (r *Renderer) processFile(ctx context.Context, path string, data Content) (string, error) {
 	// Check for bang prefix
 	if strings.HasPrefix(path, "!") {
 		// Remove the bang prefix and render as template
 		templatePath := strings.TrimPrefix(path, "!")
-		return r.RenderPartial(templatePath, data)
+		return r.renderPartial(ctx, templatePath, data)
 	}
 
 	// No bang prefix - include as raw content
@@ -214,13 +436,13 @@ func (r *Renderer) processFile(path string, data Content) (string, error) {
 
 // processFiles processes a list of files and writes their concatenated content to w
 // Each file's content is separated by at least one newline
-func (r *Renderer) processFiles(w io.Writer, files []string, data Content) error {
+func (r *Renderer) processFiles(ctx context.Context, w io.Writer, files []string, data Content) error {
 	if len(files) == 0 {
 		return nil
 	}
 
 	for i, file := range files {
-		content, err := r.processFile(file, data)
+		content, err := r.processFile(ctx, file, data)
 		if err != nil {
 			return fmt.Errorf("error processing file %s: %w", file, err)
 		}
@@ -241,11 +463,60 @@ func (r *Renderer) processFiles(w io.Writer, files []string, data Content) error
 	return nil
 }
 
+// parseBlocks parses a block front matter value of the form
+// "name=path; name2=path2" into a map of block name to template path.
+func parseBlocks(s string) (map[string]string, error) {
+	entries := splitSemicolon(s)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	blocks := make(map[string]string, len(entries))
+	for _, e := range entries {
+		name, path, ok := strings.Cut(e, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid block override %q, expected name=path", e)
+		}
+		blocks[name] = path
+	}
+	return blocks, nil
+}
+
+// loadBlocks loads the block overrides declared by t, resolved relative to t.
+// Overrides already in effect (from templates further in) take precedence.
+func (r *Renderer) loadBlocks(t *Template, inherited map[string]*Template) (map[string]*Template, error) {
+	paths, err := parseBlocks(t.FrontMatter.Block)
+	if err != nil {
+		return nil, fmt.Errorf("error in %s: %w", t.Path, err)
+	}
+	if len(paths) == 0 {
+		return inherited, nil
+	}
+
+	blocks := make(map[string]*Template, len(paths)+len(inherited))
+	for name, path := range paths {
+		bt, err := r.ctx.LoadTemplate(path, t)
+		if err != nil {
+			return nil, fmt.Errorf("error loading block %s template %s: %w", name, path, err)
+		}
+		if r.metrics != nil {
+			r.metrics.Add("template", bt.Path, []byte(bt.Body))
+		}
+		blocks[name] = bt
+	}
+	maps.Copy(blocks, inherited)
+	return blocks, nil
+}
+
 // renderTemplateInternal renders *t* then applies its wrapper layouts
 // inner-to-outer, with depth & cycle protection.
 // New layout order: [before ...]<<<layouts ...>>>[after...] [user]
+//
+// blocks holds the named block overrides that apply to t's body; t's own
+// overrides are added for its layouts.
 func (r *Renderer) renderTemplateInternal(
-	w io.Writer, t *Template, data Content, seen map[string]bool, depth int,
+	ctx context.Context, w io.Writer, t *Template, data Content, seen map[string]bool, depth int,
+	blocks map[string]*Template,
 ) error {
 
 	// ─── Safety guards ────────────────────────────────────────────────────────
@@ -291,7 +562,7 @@ func (r *Renderer) renderTemplateInternal(
 
 		// Use a counting writer to track if anything was written
 		cw := &countingWriter{w: w}
-		if err := r.processFiles(cw, beforeFiles, data); err != nil {
+		if err := r.processFiles(ctx, cw, beforeFiles, data); err != nil {
 			return fmt.Errorf("error processing before files: %w", err)
 		}
 
@@ -309,6 +580,11 @@ func (r *Renderer) renderTemplateInternal(
 
 	// ─── Apply layouts (with empty .Content for the first layout) ────────────
 	if len(layouts) > 0 {
+		layoutBlocks, err := r.loadBlocks(t, blocks)
+		if err != nil {
+			return err
+		}
+
 		// Save original content
 		prevContent := data.Content()
 
@@ -339,7 +615,7 @@ func (r *Renderer) renderTemplateInternal(
 			// Reset the buffer for the next iteration
 			layoutBuf.Reset()
 
-			if err := r.renderTemplateInternal(&layoutBuf, wrapper, data, seen, depth+1); err != nil {
+			if err := r.renderTemplateInternal(ctx, &layoutBuf, wrapper, data, seen, depth+1, layoutBlocks); err != nil {
 				r.cur = prevCur
 				return err
 			}
@@ -372,7 +648,7 @@ func (r *Renderer) renderTemplateInternal(
 
 		// Use a counting writer to track if anything was written
 		cw := &countingWriter{w: w}
-		if err := r.processFiles(cw, afterFiles, data); err != nil {
+		if err := r.processFiles(ctx, cw, afterFiles, data); err != nil {
 			return fmt.Errorf("error processing after files: %w", err)
 		}
 
@@ -389,29 +665,153 @@ func (r *Renderer) renderTemplateInternal(
 	}
 
 	// ─── Render the user content (current template body) ────────────────────
-	if err := r.executeTemplate(w, t, data); err != nil {
+	if r.trace == nil {
+		return r.executeTemplate(ctx, w, t, data, blocks)
+	}
+
+	if _, err := fmt.Fprintf(r.trace, "<!-- BEGIN template:%s -->\n", t.Path); err != nil {
 		return err
 	}
 
-	return nil
+	var out bytes.Buffer
+	if err := r.executeTemplate(ctx, io.MultiWriter(w, &out), t, data, blocks); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(r.trace, "%s<!-- END template:%s -->\n", out.Bytes(), t.Path)
+	return err
 }
 
 // executeTemplate renders a single template body with the "partial" helper.
-func (r *Renderer) executeTemplate(w io.Writer, t *Template, data Content) error {
-	tmpl, err := template.New("content").Funcs(template.FuncMap{
-		"partial": func(path string) (string, error) {
-			return r.RenderPartial(path, data)
-		},
-		"include": func(path string) (string, error) {
-			return r.Include(path)
-		},
-	}).Parse(t.Body)
-	if err != nil {
+// The template's imports are available as named sub-templates. Each entry in
+// blocks is parsed after the body, so it replaces any {{ block }} or
+// {{ define }} of the same name.
+// Output goes through a ctxWriter, so execution stops at the next write
+// once ctx is done.
+func (r *Renderer) executeTemplate(ctx context.Context, w io.Writer, t *Template, data Content, blocks map[string]*Template) error {
+	if err := checkRequired(t, data); err != nil {
+		return err
+	}
+
+	tmpl := template.New("content").Funcs(r.funcMap(ctx, data))
+
+	// Imports are parsed first so definitions in the body take precedence.
+	for _, imp := range t.Imports {
+		if _, err := tmpl.New(imp.Name).Parse(imp.Body); err != nil {
+			return fmt.Errorf("error parsing import %s in %s: %w", imp.Path, t.Path, err)
+		}
+	}
+
+	if _, err := tmpl.Parse(t.Body); err != nil {
 		return fmt.Errorf("error parsing template %s: %w", t.Path, err)
 	}
 
-	if err := tmpl.Execute(w, data); err != nil {
+	for name, bt := range blocks {
+		if _, err := tmpl.New(name).Parse(bt.Body); err != nil {
+			return fmt.Errorf("error parsing block %s template %s: %w", name, bt.Path, err)
+		}
+	}
+
+	// keep a copy of the output when the caller wants it as a section
+	var section *bytes.Buffer
+	if r.metrics != nil && r.metrics.RecordingSections() {
+		section = &bytes.Buffer{}
+		w = io.MultiWriter(w, section)
+	}
+
+	start := time.Now()
+	if err := tmpl.Execute(&ctxWriter{ctx: ctx, w: w}, data); err != nil {
 		return fmt.Errorf("error executing template %s: %w", t.Path, err)
 	}
+	if r.metrics != nil {
+		r.metrics.Add("template", t.Path, []byte(t.Body), time.Since(start))
+	}
+	if section != nil {
+		r.metrics.AddSection("template", t.Path, section.String())
+	}
+	return nil
+}
+
+// checkRequired returns an error naming the first path in t's require list
+// that is missing, nil or zero in data.
+func checkRequired(t *Template, data any) error {
+	for _, path := range t.FrontMatter.Require {
+		path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+		v, ok := lookupField(reflect.ValueOf(data), path)
+		if ok && !v.IsZero() {
+			continue
+		}
+		if key, isData := strings.CutPrefix(path, "Data."); isData && !strings.Contains(key, ".") {
+			return fmt.Errorf("template %s requires %s; pass it with --data %s=<value>", t.Path, path, key)
+		}
+		return fmt.Errorf("template %s requires %s", t.Path, path)
+	}
 	return nil
 }
+
+// lookupField follows a dot-separated path of struct fields and map keys
+// from v, dereferencing pointers and interfaces along the way. It reports
+// false if any step is missing or nil.
+func lookupField(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(name)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		default:
+			return reflect.Value{}, false
+		}
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// funcMap returns the functions available to a template body.
+// Registered functions are merged in first, so the built-ins always win.
+// The partial and include built-ins check ctx before loading anything, so a
+// cancelled render does not keep reading files in the background.
+func (r *Renderer) funcMap(ctx context.Context, data Content) template.FuncMap {
+	funcs := template.FuncMap{}
+	maps.Copy(funcs, r.funcs)
+	maps.Copy(funcs, template.FuncMap{
+		"partial": func(path string) (string, error) {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("partial %s: %w", path, err)
+			}
+			return r.renderPartial(ctx, path, data)
+		},
+		"partialWith": func(path string, data any) (string, error) {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("partialWith %s: %w", path, err)
+			}
+			return r.renderPartial(ctx, path, asContent(data))
+		},
+		// dict builds the ad-hoc data that partialWith is usually given
+		"dict": dict,
+		"include": func(path string) (string, error) {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("include %s: %w", path, err)
+			}
+			return r.Include(path)
+		},
+		"includeOnce": func(path string) (string, error) {
+			if err := ctx.Err(); err != nil {
+				return "", fmt.Errorf("includeOnce %s: %w", path, err)
+			}
+			return r.IncludeOnce(path)
+		},
+	})
+	return funcs
+}
@@ -1,13 +1,19 @@
 package render
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
+	"github.com/hayeah/fork2/ignore"
 	"github.com/hayeah/fork2/internal/assert"
+	"github.com/hayeah/fork2/internal/metrics"
 )
 
 //--------------------------------- Helper utilities ---------------------------------
@@ -53,7 +59,7 @@ func TestResolvePartialPath(t *testing.T) {
 		"components/shared/footer.md":   "footer template",
 	})
 
-	ctx := NewResolver(repoFS, systemFS)
+	ctx := NewResolver("", repoFS, systemFS)
 	assert := assert.New(t)
 
 	cases := []struct {
@@ -103,7 +109,7 @@ func TestRelativePathResolution(t *testing.T) {
 		"templates/shared/footer.md":    "shared footer",
 	})
 
-	ctx := NewResolver(repoFS)
+	ctx := NewResolver("", repoFS)
 	assert := assert.New(t)
 
 	cases := []struct {
@@ -138,6 +144,49 @@ func TestRelativePathResolution(t *testing.T) {
 	}
 }
 
+func TestLocalFSShadowsRelativePartials(t *testing.T) {
+	systemFS := createTestFS(map[string]string{
+		"vibe/page.md":   "{{ partial \"./footer\" }}",
+		"vibe/footer.md": "system footer",
+		"vibe/nav.md":    "system nav",
+	})
+	localFS := createTestFS(map[string]string{
+		"vibe/footer.md": "local footer",
+	})
+
+	ctx := NewResolver("", systemFS)
+	assert := assert.New(t)
+	cur := &Template{Path: "vibe/page.md", FS: systemFS, LocalFS: localFS}
+
+	gotFS, gotFile, err := ctx.ResolvePartialPath("./footer", cur)
+	assert.NoError(err)
+	assert.Equal(localFS, gotFS)
+	assert.Equal("vibe/footer.md", gotFile)
+
+	// paths missing from LocalFS fall back to the template's own FS
+	gotFS, gotFile, err = ctx.ResolvePartialPath("./nav", cur)
+	assert.NoError(err)
+	assert.Equal(systemFS, gotFS)
+	assert.Equal("vibe/nav.md", gotFile)
+
+	// non-relative paths ignore LocalFS
+	gotFS, _, err = ctx.ResolvePartialPath("vibe/footer", cur)
+	assert.NoError(err)
+	assert.Equal(systemFS, gotFS)
+
+	// templates loaded relative to cur inherit its LocalFS
+	tmpl, err := ctx.LoadTemplate("./footer", cur)
+	assert.NoError(err)
+	assert.Equal("local footer", tmpl.Body)
+	assert.Equal(localFS, tmpl.LocalFS)
+
+	// other loads come from elsewhere and don't
+	tmpl, err = ctx.LoadTemplate("<vibe/nav>", cur)
+	assert.NoError(err)
+	assert.Equal("system nav", tmpl.Body)
+	assert.Nil(tmpl.LocalFS)
+}
+
 func TestPartialRendering(t *testing.T) {
 	systemFS := createTestFS(map[string]string{
 		"vibe/coder":  "System {{ partial \"<vibe/footer>\" }}",
@@ -150,7 +199,7 @@ func TestPartialRendering(t *testing.T) {
 		"templates/local/helper": "Local Helper that uses {{ partial \"@common/header\" }}",
 	})
 
-	ctx := NewResolver(repoFS, systemFS)
+	ctx := NewResolver("", repoFS, systemFS)
 	renderer := NewRenderer(ctx, nil)
 	assert := assert.New(t)
 
@@ -218,7 +267,7 @@ INNER-END`,
 OUTER-END`,
 	})
 
-	ctx := NewResolver(repoFS, systemFS)
+	ctx := NewResolver("", repoFS, systemFS)
 	renderer := NewRenderer(ctx, nil)
 
 	data := &struct {
@@ -269,7 +318,7 @@ func TestLayoutCycleDetection(t *testing.T) {
 		"f.md": "---toml\nlayout = \"c.md\"\n---\nF", // f -> c (cycle through multi-layout)
 	})
 
-	ctx := NewResolver(repoFS)
+	ctx := NewResolver("", repoFS)
 	renderer := NewRenderer(ctx, nil)
 	assert := assert.New(t)
 
@@ -301,7 +350,7 @@ func TestLayoutDeepNestingLimit(t *testing.T) {
 	}
 
 	repoFS := createTestFS(files)
-	ctx := NewResolver(repoFS)
+	ctx := NewResolver("", repoFS)
 	renderer := NewRenderer(ctx, nil)
 	assert := assert.New(t)
 
@@ -321,7 +370,7 @@ func TestLoadTemplateParsesFrontMatter(t *testing.T) {
 		"bar.md":  "---toml\nlayout=\"base.md\"\nselect=\"*.go\"\ndirtree=\"cmd/;internal/\"\n---\nContent",
 		"base.md": "Base {{ .Content }}",
 	})
-	ctx := NewResolver(repoFS)
+	ctx := NewResolver("", repoFS)
 	assert := assert.New(t)
 
 	// Test basic layout parsing
@@ -337,6 +386,78 @@ func TestLoadTemplateParsesFrontMatter(t *testing.T) {
 	assert.Equal("cmd/;internal/", tmpl.FrontMatter.Dirtree)
 }
 
+func TestLoadFrontMatter(t *testing.T) {
+	repoFS := createTestFS(map[string]string{
+		"foo.md": "---toml\ndescription = \"Explain the code\"\nimport = [\"missing.md\"]\n---\n{{ if }}",
+		"bar.md": "No front matter",
+	})
+	systemFS := createTestFS(map[string]string{
+		"foo.md": "---toml\ndescription = \"builtin\"\n---\n",
+	})
+	ctx := NewResolver("", repoFS, systemFS)
+	assert := assert.New(t)
+
+	// the body and imports are never looked at
+	meta, err := ctx.LoadFrontMatter("foo")
+	assert.NoError(err)
+	assert.Equal("Explain the code", meta.Description)
+
+	meta, err = ctx.LoadFrontMatter("bar.md")
+	assert.NoError(err)
+	assert.Equal(FrontMatter{}, meta)
+
+	_, err = ctx.LoadFrontMatter("nope.md")
+	assert.Error(err)
+
+	// LoadTemplateMeta resolves and parses the same way
+	ptr, err := ctx.LoadTemplateMeta("foo")
+	assert.NoError(err)
+	assert.Equal("Explain the code", ptr.Description)
+	ptr, err = ctx.LoadTemplateMeta("nope.md")
+	assert.Error(err)
+	assert.Nil(ptr)
+}
+
+func TestListTemplates(t *testing.T) {
+	repoFS := fstest.MapFS{
+		"review.md":         {Data: []byte("---toml\ntags = [\"coder\", \"review\"]\n---\nbody")},
+		"prompts/audit.md":  {Data: []byte("---\ntags:\n  - review\n---\nbody")},
+		"plain.md":          {Data: []byte("no front matter")},
+		"shadow.md":         {Data: []byte("---toml\ntags = [\"draft\"]\n---\n")},
+		"broken.md":         {Data: []byte("---toml\ntags = [\n---\n")},
+		".hidden/review.md": {Data: []byte("---toml\ntags = [\"review\"]\n---\n")},
+		"notes/readme.txt":  {Data: []byte("not a template")},
+	}
+	systemFS := fstest.MapFS{
+		"shadow.md":  {Data: []byte("---toml\ntags = [\"review\"]\n---\n")},
+		"builtin.md": {Data: []byte("---toml\ntags = [\"review\", \"builtin\"]\n---\n")},
+	}
+	ctx := NewResolver("", repoFS, systemFS)
+	assert := assert.New(t)
+
+	paths, err := ctx.ListTemplates("review")
+	// broken front matter is reported, and the other matches still listed
+	assert.ErrorContains(err, "broken.md: failed to parse TOML")
+	// shadow.md is judged by the repo copy, which is not tagged review
+	assert.Equal([]string{"builtin.md", "prompts/audit.md", "review.md"}, paths)
+
+	paths, err = ctx.ListTemplates("coder")
+	assert.Error(err)
+	assert.Equal([]string{"review.md"}, paths)
+
+	paths, err = ctx.ListTemplates("")
+	assert.NoError(err)
+	assert.Equal([]string{"broken.md", "builtin.md", "plain.md", "prompts/audit.md", "review.md", "shadow.md"}, paths)
+
+	// ignored paths are skipped, in the filesystem the rules belong to
+	ig, err := ignore.NewIgnoreFromPatterns(".", []string{"prompts/", "broken.md"})
+	assert.NoError(err)
+	ctx.Ignores = []*ignore.Ignore{ig}
+	paths, err = ctx.ListTemplates("review")
+	assert.NoError(err)
+	assert.Equal([]string{"builtin.md", "review.md"}, paths)
+}
+
 // TestTemplatePrecedenceOrder verifies that when the same template exists in multiple
 // filesystem layers, it's resolved from the highest-priority layer according to the
 // precedence order: repo → VIBE_PROMPTS → ~/.vibe → built-in templates
@@ -369,7 +490,7 @@ func TestTemplatePrecedenceOrder(t *testing.T) {
 	})
 
 	// Create resolver with all layers in the correct order
-	ctx := NewResolver(repoFS, vibePromptsFS, userVibeFS, systemFS)
+	ctx := NewResolver("", repoFS, vibePromptsFS, userVibeFS, systemFS)
 	renderer := NewRenderer(ctx, nil)
 	assert := assert.New(t)
 
@@ -414,7 +535,7 @@ func TestInclude(t *testing.T) {
 		"templates/current/local.txt": "LOCAL",
 	})
 
-	ctx := NewResolver(repoFS, systemFS)
+	ctx := NewResolver("", repoFS, systemFS)
 	renderer := NewRenderer(ctx, nil)
 	assert := assert.New(t)
 
@@ -439,3 +560,364 @@ func TestInclude(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal("RAW {{ partial \"<vibe/sys.txt>\" }}", strings.TrimSpace(out))
 }
+
+func TestRenderTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"main.md": `before {{ partial "slow.md" }} after`,
+		"slow.md": `{{ sleep }}slow`,
+		"fast.md": `fast`,
+	})
+
+	ctx := NewResolver("", repoFS)
+	renderer := NewRenderer(ctx, nil).WithTimeout(20 * time.Millisecond)
+	release := make(chan struct{})
+	renderer.RegisterFunc("sleep", func() string {
+		select {
+		case <-release:
+		case <-time.After(500 * time.Millisecond):
+		}
+		return ""
+	})
+
+	start := time.Now()
+	_, err := renderer.Render("main.md", &testContent{})
+	assert.Error(err)
+	assert.True(errors.Is(err, context.DeadlineExceeded), "expected DeadlineExceeded, got %v", err)
+	assert.Less(time.Since(start), 400*time.Millisecond)
+
+	// The renderer can be reused while the abandoned render finishes
+	out, err := renderer.Render("fast.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal("fast", out)
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+
+	// Renders that finish in time are unaffected
+	out, err = NewRenderer(ctx, nil).WithTimeout(time.Second).Render("fast.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal("fast", out)
+
+	// A cancelled parent context stops rendering too
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewRenderer(ctx, nil).RenderWithContext(cancelled, "fast.md", &testContent{})
+	assert.True(errors.Is(err, context.Canceled), "expected Canceled, got %v", err)
+}
+
+func TestFuncMapStopsWhenCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"part.md": "part",
+	})
+	renderer := NewRenderer(NewResolver("", repoFS), nil)
+	data := &testContent{}
+
+	live := renderer.funcMap(context.Background(), data)
+	out, err := live["partial"].(func(string) (string, error))("part.md")
+	assert.NoError(err)
+	assert.Equal("part", out)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	funcs := renderer.funcMap(cancelled, data)
+
+	for _, name := range []string{"partial", "include", "includeOnce"} {
+		_, err := funcs[name].(func(string) (string, error))("part.md")
+		assert.True(errors.Is(err, context.Canceled), "%s: expected Canceled, got %v", name, err)
+	}
+	_, err = funcs["partialWith"].(func(string, any) (string, error))("part.md", nil)
+	assert.True(errors.Is(err, context.Canceled), "partialWith: expected Canceled, got %v", err)
+
+	// includeOnce must not mark the file as seen when it was cancelled
+	assert.False(renderer.included["part.md"])
+}
+
+func TestIncludeOnce(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"main.md": "---toml\nlayout = \"layout.md\"\n---\n" +
+			"MAIN {{ partial \"child.md\" }}{{ includeOnce \"common/footer.md\" }}",
+		"layout.md":        "LAYOUT {{ includeOnce \"@common/footer.md\" }}",
+		"child.md":         "CHILD {{ includeOnce \"common/footer\" }}",
+		"common/footer.md": "FOOTER",
+		"plain.md":         "{{ include \"common/footer.md\" }} {{ include \"common/footer.md\" }}",
+	})
+
+	ctx := NewResolver("", repoFS)
+	renderer := NewRenderer(ctx, nil)
+
+	out, err := renderer.Render("main.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal(1, strings.Count(out, "FOOTER"), out)
+	assert.Equal("LAYOUT FOOTER\nMAIN CHILD ", out)
+
+	// The dedup set is scoped to a single render
+	out, err = renderer.Render("main.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal(1, strings.Count(out, "FOOTER"), out)
+
+	// Plain include is unaffected
+	out, err = renderer.Render("plain.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal("FOOTER FOOTER", out)
+}
+
+func TestPartialWith(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"main.md":    `{{ partialWith "./item.md" .Item }}|{{ partialWith "./item.md" (dict "Name" "dict") }}|{{ partialWith "./content.md" . }}`,
+		"item.md":    `item={{ .Data.Name }}`,
+		"content.md": `value={{ .Value }}`,
+	})
+
+	data := &struct {
+		*testContent
+		Value string
+		Item  map[string]string
+	}{
+		testContent: &testContent{},
+		Value:       "parent",
+		Item:        map[string]string{"Name": "field"},
+	}
+
+	// dict is built in, without RegisterFuncs(DefaultFuncs())
+	renderer := NewRenderer(NewResolver("", repoFS), nil)
+
+	out, err := renderer.Render("main.md", data)
+	assert.NoError(err)
+	assert.Equal("item=field|item=dict|value=parent", out)
+
+	out, err = renderer.RenderPartialWithData("item.md", struct{ Name string }{"direct"})
+	assert.NoError(err)
+	assert.Equal("item=direct", out)
+
+	// AnyContent still carries .Content for layout wrapping.
+	c := &AnyContent{Data: 1}
+	c.SetContent("inner")
+	assert.Equal("inner", c.Content())
+}
+
+func TestBlockOverrides(t *testing.T) {
+	repoFS := createTestFS(map[string]string{
+		"layouts/base.md": `[{{ block "sidebar" . }}default sidebar{{ end }}|{{ block "footer" . }}default footer{{ end }}]`,
+		"layouts/outer.md": "---toml\nlayout = \"layouts/base.md\"\nblock = \"footer=layouts/outer_footer.md\"\n---\n" +
+			`outer {{ block "sidebar" . }}outer default{{ end }}`,
+		"layouts/outer_footer.md": "outer footer",
+
+		"plain.md":    "---toml\nlayout = \"layouts/base.md\"\n---\nbody",
+		"override.md": "---toml\nlayout = \"layouts/base.md\"\nblock = \"sidebar=./parts/sidebar.md\"\n---\nbody",
+		"nested.md":   "---toml\nlayout = \"layouts/outer.md\"\nblock = \"sidebar=./parts/sidebar.md\"\n---\nbody",
+		"bad.md":      "---toml\nlayout = \"layouts/base.md\"\nblock = \"sidebar=./parts/missing.md\"\n---\nbody",
+
+		"parts/sidebar.md": `custom sidebar {{ .Value }}`,
+	})
+
+	renderer := NewRenderer(NewResolver("", repoFS), nil)
+	newData := func() *struct {
+		*testContent
+		Value string
+	} {
+		return &struct {
+			*testContent
+			Value string
+		}{&testContent{}, "v"}
+	}
+
+	t.Run("layout defaults", func(t *testing.T) {
+		assert := assert.New(t)
+		out, err := renderer.Render("plain.md", newData())
+		assert.NoError(err)
+		assert.Equal("[default sidebar|default footer]\nbody", out)
+	})
+
+	t.Run("override replaces default block", func(t *testing.T) {
+		assert := assert.New(t)
+		out, err := renderer.Render("override.md", newData())
+		assert.NoError(err)
+		assert.Equal("[custom sidebar v|default footer]\nbody", out)
+	})
+
+	t.Run("overrides apply through nested layouts", func(t *testing.T) {
+		assert := assert.New(t)
+		out, err := renderer.Render("nested.md", newData())
+		assert.NoError(err)
+		assert.Equal("[custom sidebar v|outer footer]\nouter custom sidebar v\nbody", out)
+	})
+
+	t.Run("missing override template", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := renderer.Render("bad.md", newData())
+		assert.Error(err)
+		assert.Contains(err.Error(), "error loading block sidebar template")
+	})
+}
+
+func TestRequire(t *testing.T) {
+	repoFS := createTestFS(map[string]string{
+		"main.md":   "---toml\nrequire = [\"Data.ProjectName\", \"Data.Env\"]\n---\n{{ .Data.ProjectName }}/{{ .Data.Env }}",
+		"nested.md": "---toml\nrequire = [\"Config.Owner.Name\"]\n---\n{{ .Config.Owner.Name }}",
+	})
+
+	type owner struct{ Name string }
+	type data struct {
+		*testContent
+		Data   map[string]string
+		Config struct{ Owner *owner }
+	}
+
+	renderer := NewRenderer(NewResolver("", repoFS), nil)
+
+	t.Run("all present", func(t *testing.T) {
+		assert := assert.New(t)
+		out, err := renderer.Render("main.md", &data{testContent: &testContent{}, Data: map[string]string{"ProjectName": "vibe", "Env": "dev"}})
+		assert.NoError(err)
+		assert.Equal("vibe/dev", out)
+	})
+
+	t.Run("missing data key", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := renderer.Render("main.md", &data{testContent: &testContent{}, Data: map[string]string{"ProjectName": "vibe"}})
+		assert.ErrorContains(err, "requires Data.Env; pass it with --data Env=<value>")
+	})
+
+	t.Run("empty data value", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := renderer.Render("main.md", &data{testContent: &testContent{}, Data: map[string]string{"ProjectName": "", "Env": "dev"}})
+		assert.ErrorContains(err, "requires Data.ProjectName")
+	})
+
+	t.Run("nil pointer on path", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := renderer.Render("nested.md", &data{testContent: &testContent{}})
+		assert.ErrorContains(err, "template nested.md requires Config.Owner.Name")
+
+		d := &data{testContent: &testContent{}}
+		d.Config.Owner = &owner{Name: "ann"}
+		out, err := renderer.Render("nested.md", d)
+		assert.NoError(err)
+		assert.Equal("ann", out)
+	})
+}
+
+func TestImports(t *testing.T) {
+	repoFS := createTestFS(map[string]string{
+		"common/definitions.md": "---toml\nselect = \"ignored\"\n---\n" +
+			`{{ define "greet" }}Hello {{ .Value }}{{ end }}shared {{ .Value }}`,
+		"templates/helpers.md": `helper`,
+		"templates/main.md": "---toml\nimport = [\"@common/definitions.md\", \"./helpers\"]\n---\n" +
+			`{{ template "definitions" . }}|{{ template "greet" . }}|{{ template "helpers" }}`,
+		"templates/override.md": "---\nimport:\n  - \"@common/definitions.md\"\n---\n" +
+			`{{ define "greet" }}Hi{{ end }}{{ template "greet" . }}`,
+		"templates/missing.md": "---toml\nimport = [\"./nope.md\"]\n---\nbody",
+	})
+
+	renderer := NewRenderer(NewResolver("", repoFS), nil)
+	data := &struct {
+		*testContent
+		Value string
+	}{&testContent{}, "v"}
+
+	t.Run("imported templates are callable", func(t *testing.T) {
+		assert := assert.New(t)
+		out, err := renderer.Render("templates/main.md", data)
+		assert.NoError(err)
+		assert.Equal("shared v|Hello v|helper", out)
+	})
+
+	t.Run("body definitions win over imports", func(t *testing.T) {
+		assert := assert.New(t)
+		out, err := renderer.Render("templates/override.md", data)
+		assert.NoError(err)
+		assert.Equal("Hi", out)
+	})
+
+	t.Run("missing import", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := renderer.Render("templates/missing.md", data)
+		assert.Error(err)
+		assert.Contains(err.Error(), `error loading import "./nope.md"`)
+	})
+}
+
+func TestWithTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"layout.md": "<layout>{{ .Content }}</layout>\n",
+		"main.md": `---
+layout = "layout.md"
+---
+main {{ partial "item.md" }}
+`,
+		"item.md": "item",
+	})
+
+	var trace bytes.Buffer
+	out, err := NewRenderer(NewResolver("", repoFS), nil).WithTracing(&trace).Render("main.md", &testContent{})
+	assert.NoError(err)
+	assert.Equal("<layout></layout>\n\nmain item\n", out)
+
+	markers := []string{
+		"<!-- BEGIN template:layout.md -->",
+		"</layout>\n<!-- END template:layout.md -->",
+		"<!-- BEGIN template:main.md -->",
+		"<!-- BEGIN template:item.md -->",
+		"item<!-- END template:item.md -->",
+		"main item\n<!-- END template:main.md -->",
+	}
+	traced := trace.String()
+	last := -1
+	for _, m := range markers {
+		idx := strings.Index(traced, m)
+		assert.Greater(idx, last, "marker %q out of order in:\n%s", m, traced)
+		last = idx
+	}
+
+	// A nil writer disables tracing
+	out, err = NewRenderer(NewResolver("", repoFS), nil).WithTracing(nil).Render("main.md", &testContent{})
+	assert.NoError(err)
+	assert.NotContains(out, "<!--")
+}
+
+func TestTemplateTiming(t *testing.T) {
+	assert := assert.New(t)
+
+	repoFS := createTestFS(map[string]string{
+		"main.md":   `main {{ partial "slow.md" }}{{ partial "slow.md" }}`,
+		"slow.md":   `{{ sleep }}slow`,
+		"broken.md": `{{ fail }}`,
+	})
+
+	m := metrics.NewOutputMetrics(&metrics.SimpleCounter{}, 1)
+	renderer := NewRenderer(NewResolver("", repoFS), m)
+	renderer.RegisterFunc("sleep", func() string {
+		time.Sleep(5 * time.Millisecond)
+		return ""
+	})
+	renderer.RegisterFunc("fail", func() (string, error) {
+		return "", errors.New("boom")
+	})
+
+	_, err := renderer.Render("main.md", &testContent{})
+	assert.NoError(err)
+	_, err = renderer.Render("broken.md", &testContent{})
+	assert.Error(err)
+	m.Wait()
+
+	slow := m.Items[metrics.NewKey("template", "slow.md")]
+	main := m.Items[metrics.NewKey("template", "main.md")]
+	// a partial rendered twice reports the time of both renders
+	assert.GreaterOrEqual(slow.Duration, 10*time.Millisecond)
+	// a template's time includes the partials it calls
+	assert.GreaterOrEqual(main.Duration, slow.Duration)
+
+	// templates that fail to execute are still counted
+	broken, ok := m.Items[metrics.NewKey("template", "broken.md")]
+	assert.True(ok)
+	assert.Zero(broken.Duration)
+}
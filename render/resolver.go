@@ -1,11 +1,16 @@
 package render
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+
+	"github.com/hayeah/fork2/ignore"
 )
 
 // Resolver lookups available template paths across multiple filesystems
@@ -13,6 +18,11 @@ type Resolver struct {
 	// Search‑order stack of filesystems – first = highest priority, last = builtin defaults
 	Partials []fs.FS
 	Mode     string
+
+	// Ignores holds the ignore rules of each filesystem in Partials, by index.
+	// ListTemplates skips the paths they match; a missing or nil entry
+	// ignores nothing.
+	Ignores []*ignore.Ignore
 }
 
 // LoadTemplate loads a template from a path and returns the template.
@@ -31,10 +41,148 @@ func (r *Resolver) LoadTemplate(path string, cur *Template) (*Template, error) {
 
 	// Set the original path (not the resolved file path) as the template path
 	tmpl.Path = path
+	// Relative loads stay beside cur, so they keep using its LocalFS
+	if cur != nil && isRelativePath(path) {
+		tmpl.LocalFS = cur.LocalFS
+	}
+
+	for _, ip := range tmpl.FrontMatter.Import {
+		imp, err := r.loadImport(ip, tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("error loading import %q in %s: %w", ip, path, err)
+		}
+		tmpl.Imports = append(tmpl.Imports, imp)
+	}
 
 	return tmpl, nil
 }
 
+// LoadFrontMatter resolves path like LoadTemplate but only parses the front
+// matter. Imports are not loaded and the body is discarded.
+func (r *Resolver) LoadFrontMatter(path string) (FrontMatter, error) {
+	fsys, filePath, err := r.ResolvePartialPath(path, nil)
+	if err != nil {
+		return FrontMatter{}, fmt.Errorf("error resolving partial path %q: %w", path, err)
+	}
+
+	blob, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return FrontMatter{}, err
+	}
+
+	tag, rawFM, _, err := ParseFrontMatter(string(blob))
+	if err != nil {
+		return FrontMatter{}, err
+	}
+
+	var meta FrontMatter
+	if rawFM != "" {
+		if err := ParseFrontMatterContent(tag, rawFM, &meta); err != nil {
+			return FrontMatter{}, err
+		}
+	}
+	return meta, nil
+}
+
+// LoadTemplateMeta is LoadFrontMatter returning a pointer, for callers that
+// scan many templates and only need their metadata.
+func (r *Resolver) LoadTemplateMeta(path string) (*FrontMatter, error) {
+	meta, err := r.LoadFrontMatter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// ListTemplates returns the sorted paths of the .md templates in all
+// filesystems whose front matter tags include tag; an empty tag lists every
+// template. Hidden directories and paths matched by Ignores are skipped. A
+// path present in several filesystems is listed once and judged by the copy
+// that resolution picks.
+//
+// Only front matter is read. A template whose front matter fails to parse is
+// left out, and its error is returned joined with the others, along with the
+// templates that did match.
+func (r *Resolver) ListTemplates(tag string) ([]string, error) {
+	seen := map[string]bool{}
+	for i, fsys := range r.Partials {
+		var ig *ignore.Ignore
+		if i < len(r.Ignores) {
+			ig = r.Ignores[i]
+		}
+		err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ig != nil && ig.IsIgnoredRel(path, d.IsDir()) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if path != "." && strings.HasPrefix(d.Name(), ".") {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".md") {
+				seen[path] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	var errs []error
+	for p := range seen {
+		if tag != "" {
+			meta, err := r.LoadTemplateMeta(p)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p, err))
+				continue
+			}
+			if !slices.Contains(meta.Tags, tag) {
+				continue
+			}
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return paths, errors.Join(errs...)
+}
+
+// loadImport reads an imported file, resolved relative to cur.
+func (r *Resolver) loadImport(path string, cur *Template) (Import, error) {
+	fsys, filePath, err := r.ResolvePartialPath(path, cur)
+	if err != nil {
+		return Import{}, err
+	}
+
+	blob, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return Import{}, err
+	}
+
+	_, _, body, err := ParseFrontMatter(string(blob))
+	if err != nil {
+		return Import{}, err
+	}
+
+	return Import{Name: importName(path), Path: path, Body: body}, nil
+}
+
+// importName derives the sub-template name for an import path:
+// "@common/definitions.md" and "<vibe/definitions>" both become "definitions".
+func importName(path string) string {
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "<"), ">")
+	return strings.TrimSuffix(filepath.Base(path), ".md")
+}
+
 // NewResolver creates a new Resolver with the given filesystem stack.
 func NewResolver(mode string, partials ...fs.FS) *Resolver {
 	return &Resolver{Partials: partials, Mode: mode}
@@ -88,16 +236,17 @@ func (r *Resolver) resolveTemplateFile(base string, filesystems ...fs.FS) (fs.FS
 // 1. Absolute path /path/to/template.md - use OS filesystem directly
 // 2. System Template <vibe/coder> - use the last FS in Partials
 // 3. Repo Root Template @common/header - use the first FS in Partials
-// 4. Local Template ./helpers/buttons - use the same FS as the current template
+// 4. Local Template ./helpers/buttons - use the current template's LocalFS, then its FS
 // 5. Bare path common/header - search all FS in order until found
 // Callers may omit the `.md` extension; the resolver will look for both *name* and *name.md*, but only when *name* has no extension.
 func (ctx *Resolver) ResolvePartialPath(partialPath string, cur *Template) (fs.FS, string, error) {
 	// Derive curPath/curFS from cur (if cur == nil, pass empty string / nil)
 	currentTemplatePath := ""
-	var currentTemplateFS fs.FS
+	var currentTemplateFS, localFS fs.FS
 	if cur != nil {
 		currentTemplatePath = cur.Path
 		currentTemplateFS = cur.FS
+		localFS = cur.LocalFS
 	}
 
 	// Allow "./path" as repo-root shorthand when no template is yet in play.
@@ -132,12 +281,12 @@ func (ctx *Resolver) ResolvePartialPath(partialPath string, cur *Template) (fs.F
 		fsys := ctx.Partials[0]
 		return ctx.resolveTemplateFile(path, fsys)
 
-	case strings.HasPrefix(partialPath, "./") || strings.HasPrefix(partialPath, "../") || partialPath == "." || partialPath == "..":
+	case isRelativePath(partialPath):
 		// Local template (relative to current template)
 		if currentTemplatePath == "" {
 			return nil, "", fmt.Errorf("cannot resolve relative path without currentTemplatePath")
 		}
-		if currentTemplateFS == nil {
+		if currentTemplateFS == nil && localFS == nil {
 			return nil, "", fmt.Errorf("cannot resolve relative path without currentTemplateFS")
 		}
 
@@ -147,7 +296,15 @@ func (ctx *Resolver) ResolvePartialPath(partialPath string, cur *Template) (fs.F
 		// Resolve the path relative to the current template
 		fullPath := filepath.Join(currentDir, partialPath)
 		fullPath = filepath.Clean(fullPath)
-		return ctx.resolveTemplateFile(fullPath, currentTemplateFS)
+
+		// LocalFS shadows the template's own FS, whatever its priority
+		var filesystems []fs.FS
+		for _, fsys := range []fs.FS{localFS, currentTemplateFS} {
+			if fsys != nil {
+				filesystems = append(filesystems, fsys)
+			}
+		}
+		return ctx.resolveTemplateFile(fullPath, filesystems...)
 
 	default:
 		// Bare path - search through all filesystems in order
@@ -159,3 +316,9 @@ func (ctx *Resolver) ResolvePartialPath(partialPath string, cur *Template) (fs.F
 		return ctx.resolveTemplateFile(partialPath, ctx.Partials...)
 	}
 }
+
+// isRelativePath reports whether partialPath is resolved relative to the
+// current template.
+func isRelativePath(partialPath string) bool {
+	return strings.HasPrefix(partialPath, "./") || strings.HasPrefix(partialPath, "../") || partialPath == "." || partialPath == ".."
+}
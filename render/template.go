@@ -6,32 +6,55 @@ import (
 
 // FrontMatter contains metadata parsed from template frontmatter
 type FrontMatter struct {
-	Layout  string `toml:"layout"`
-	Select  string `toml:"select"`
-	Dirtree string `toml:"dirtree"`
-	Before  string `toml:"before"`
-	After   string `toml:"after"`
-	Mode    string `toml:"mode"`
+	Layout  string `toml:"layout" yaml:"layout"`
+	Select  string `toml:"select" yaml:"select"`
+	Exclude string `toml:"exclude" yaml:"exclude"` // removes paths matched by select
+	Dirtree string `toml:"dirtree" yaml:"dirtree"`
+	Before  string `toml:"before" yaml:"before"`
+	After   string `toml:"after" yaml:"after"`
+	Mode    string `toml:"mode" yaml:"mode"`
+	// Description is a one-line summary shown by `vibe new --list`
+	Description string `toml:"description" yaml:"description"`
+	// Tags categorise the template for Resolver.ListTemplates
+	Tags []string `toml:"tags" yaml:"tags"`
+	// Block overrides named blocks in the layout chain: "sidebar=./sidebar.md; footer=@foot"
+	Block string `toml:"block" yaml:"block"`
+	// Import lists files whose bodies share this template's namespace
+	Import []string `toml:"import" yaml:"import"`
+	// Require lists dot-paths into the data, e.g. "Data.ProjectName", that
+	// must be set before the body is executed
+	Require []string `toml:"require" yaml:"require"`
+	// MaxFiles caps how many selected files are kept, in path order (0 = no limit)
+	MaxFiles int `toml:"max_files" yaml:"max_files"`
+}
+
+// Import is a file listed in a template's import front matter.
+type Import struct {
+	Name string // sub-template name: the file's basename without .md
+	Path string // path as written in the front matter
+	Body string // content with front-matter stripped
 }
 
 // Template represents a template with its content and metadata
 type Template struct {
 	Path           string      // repo-relative
 	Body           string      // content with front-matter stripped
-	FrontMatter    FrontMatter // parsed TOML front-matter (zero if none)
+	FrontMatter    FrontMatter // parsed TOML/YAML front-matter (zero if none)
 	RawFrontMatter string      // full unparsed front-matter block, empty when none
 	FS             fs.FS       // filesystem where the template was found
+	LocalFS        fs.FS       // if set, searched before FS for ./ and ../ partials
+	Imports        []Import    // loaded by Resolver.LoadTemplate
 }
 
 func NewTemplate(content string) (*Template, error) {
-	_, rawFM, body, err := ParseFrontMatter(content)
+	tag, rawFM, body, err := ParseFrontMatter(content)
 	if err != nil {
 		return nil, err
 	}
 
 	var meta FrontMatter
 	if rawFM != "" {
-		if err := ParseToml(rawFM, &meta); err != nil {
+		if err := ParseFrontMatterContent(tag, rawFM, &meta); err != nil {
 			return nil, err
 		}
 	}
@@ -47,7 +70,7 @@ func NewTemplate(content string) (*Template, error) {
 
 // LoadTemplateFS is a helper that loads a template from a given filesystem.
 //
-// Front-matter is parsed with existing ParseFrontMatter / ParseToml helpers.
+// Front-matter is parsed with existing ParseFrontMatter / ParseFrontMatterContent helpers.
 func LoadTemplateFS(path string, fsys fs.FS) (*Template, error) {
 	blob, err := fs.ReadFile(fsys, path)
 	if err != nil {
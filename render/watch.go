@@ -0,0 +1,129 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hayeah/fork2/ignore"
+)
+
+// watchDebounce is how long Watch waits for changes to settle before
+// calling onChange.
+var watchDebounce = 100 * time.Millisecond
+
+// watchRoot is a directory passed to Watch, with the ignore rules read
+// from its .gitignore and .vibeIgnore files.
+type watchRoot struct {
+	path string
+	ig   *ignore.Ignore
+}
+
+// Watch watches paths for changes and calls onChange once a burst of changes
+// has settled. Directories are watched recursively, skipping hidden and
+// ignored directories; directories created later are picked up as they
+// appear. Watcher errors are logged rather than ending the watch.
+//
+// Watch blocks until ctx is done, in which case it returns nil.
+func Watch(ctx context.Context, paths []string, onChange func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating watcher: %w", err)
+	}
+	defer w.Close()
+
+	var roots []watchRoot
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return fmt.Errorf("error watching %s: %w", p, err)
+		}
+		if !fi.IsDir() {
+			if err := w.Add(p); err != nil {
+				return fmt.Errorf("error watching %s: %w", p, err)
+			}
+			continue
+		}
+
+		ig, err := ignore.NewIgnore(p)
+		if err != nil {
+			return err
+		}
+		root := watchRoot{path: p, ig: ig}
+		if err := root.watchTree(w, p); err != nil {
+			return err
+		}
+		roots = append(roots, root)
+	}
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			if ev.Has(fsnotify.Create) {
+				if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+					if root, ok := rootOf(roots, ev.Name); ok {
+						if err := root.watchTree(w, ev.Name); err != nil {
+							log.Printf("watch error: %v", err)
+						}
+					}
+				}
+			}
+			timer.Reset(watchDebounce)
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("watch error: %v", err)
+
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
+
+// rootOf returns the watched root that contains path.
+func rootOf(roots []watchRoot, path string) (watchRoot, bool) {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root.path, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return root, true
+		}
+	}
+	return watchRoot{}, false
+}
+
+// watchTree adds dir and every directory below it to w, skipping hidden
+// directories and those the root's ignore rules exclude.
+func (root watchRoot) watchTree(w *fsnotify.Watcher, dir string) error {
+	return root.ig.WalkDir(dir, func(path string, d os.DirEntry, isDir bool) error {
+		if !isDir {
+			return nil
+		}
+		if path != root.path && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := w.Add(path); err != nil {
+			return fmt.Errorf("error watching %s: %w", path, err)
+		}
+		return nil
+	})
+}
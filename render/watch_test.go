@@ -0,0 +1,79 @@
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hayeah/fork2/internal/assert"
+)
+
+func TestWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(dir, ".hidden"), 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "node_modules", "pkg"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules/\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, []string{dir}, func() { changes <- struct{}{} })
+	}()
+
+	// Give the watcher a moment to register directories.
+	time.Sleep(50 * time.Millisecond)
+
+	expectChange := func(msg string) {
+		select {
+		case <-changes:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no change reported: %s", msg)
+		}
+	}
+	expectQuiet := func(msg string) {
+		select {
+		case <-changes:
+			t.Fatalf("unexpected change reported: %s", msg)
+		case <-time.After(3 * watchDebounce):
+		}
+	}
+
+	// A burst of writes is reported once.
+	for i := 0; i < 5; i++ {
+		assert.NoError(os.WriteFile(filepath.Join(dir, "a.md"), []byte{byte('a' + i)}, 0644))
+	}
+	expectChange("burst")
+	expectQuiet("burst should be debounced")
+
+	assert.NoError(os.WriteFile(filepath.Join(dir, "sub", "b.md"), []byte("b"), 0644))
+	expectChange("nested file")
+
+	// Directories created after Watch starts are watched too.
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "new"), 0755))
+	expectChange("new dir")
+	assert.NoError(os.WriteFile(filepath.Join(dir, "new", "c.md"), []byte("c"), 0644))
+	expectChange("file in new dir")
+
+	assert.NoError(os.WriteFile(filepath.Join(dir, ".hidden", "d.md"), []byte("d"), 0644))
+	expectQuiet("hidden dir")
+
+	// Ignored directories are not watched.
+	assert.NoError(os.WriteFile(filepath.Join(dir, "node_modules", "pkg", "e.js"), []byte("e"), 0644))
+	expectQuiet("ignored dir")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch did not return after cancel")
+	}
+}